@@ -4,7 +4,11 @@
 
 package binding
 
-import "net/http"
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
 
 // Content-Type MIME of the most common data formats.
 const (
@@ -66,6 +70,10 @@ func SetValidator(newValidator StructValidator) {
 	Validator = newValidator
 }
 
+// ErrEmptyBody is returned when a body binding, such as JSON, is used on a request without body.
+// It wraps io.EOF, the error the decoders return for an empty body.
+var ErrEmptyBody = fmt.Errorf("binding: empty request body: %w", io.EOF)
+
 // These implement the Binding interface and can be used to bind the data
 // present in the request to struct instances.
 var (
@@ -77,8 +85,54 @@ var (
 	FormMultipart = formMultipartBinding{}
 	Uri           = uriBinding{}
 	Header        = headerBinding{}
+	Request       = requestBinding{}
+	Plain         = plainBinding{}
 )
 
+var registered = map[string]Binding{}
+
+// Register makes Default return b for the given content type, e.g.
+// binding.Register("application/cbor", cborBinding{}). It takes precedence over
+// the built-in bindings and should be called once at init, it is not safe for
+// concurrent use.
+func Register(mime string, b Binding) {
+	if b == nil {
+		panic("binding: Register binding is nil")
+	}
+	registered[mime] = b
+}
+
+// Unregister removes the binding registered for the given content type, e.g. at the
+// end of a test, the built-in binding is used again. It is not safe for concurrent use.
+func Unregister(mime string) {
+	delete(registered, mime)
+}
+
+var mimeAliases = map[string]string{
+	"text/json": MIMEJSON,
+}
+
+// RegisterMIMEAlias makes alias be treated as mime when selecting the binding and by the
+// content negotiation, e.g. "text/json" is an alias of "application/json" by default.
+// It should be called once at init, it is not safe for concurrent use.
+func RegisterMIMEAlias(alias, mime string) {
+	mimeAliases[alias] = mime
+}
+
+// UnregisterMIMEAlias removes the given alias, e.g. at the end of a test.
+// It is not safe for concurrent use.
+func UnregisterMIMEAlias(alias string) {
+	delete(mimeAliases, alias)
+}
+
+// CanonicalMIME returns the MIME type registered for the alias, or mime itself.
+func CanonicalMIME(mime string) string {
+	if canonical, ok := mimeAliases[mime]; ok {
+		return canonical
+	}
+	return mime
+}
+
 // Default returns the appropriate Binding instance based on the HTTP method
 // and the content type.
 func Default(method, contentType string) Binding {
@@ -86,6 +140,11 @@ func Default(method, contentType string) Binding {
 		return Form
 	}
 
+	contentType = CanonicalMIME(contentType)
+	if b, ok := registered[contentType]; ok {
+		return b
+	}
+
 	switch contentType {
 	case MIMEJSON:
 		return JSON
@@ -93,6 +152,8 @@ func Default(method, contentType string) Binding {
 		return XML
 	case MIMEMultipartPOSTForm:
 		return FormMultipart
+	case MIMEPlain:
+		return Plain
 	default: // case MIMEPOSTForm:
 		return Form
 	}
@@ -156,6 +156,62 @@ func TestBindingDefault(t *testing.T) {
 
 	assert.Equal(t, FormMultipart, Default("POST", MIMEMultipartPOSTForm))
 	assert.Equal(t, FormMultipart, Default("PUT", MIMEMultipartPOSTForm))
+
+	assert.Equal(t, Plain, Default("POST", MIMEPlain))
+}
+
+func TestBindingMIMEAlias(t *testing.T) {
+	assert.Equal(t, MIMEJSON, CanonicalMIME("text/json"))
+	assert.Equal(t, MIMEXML, CanonicalMIME(MIMEXML))
+	assert.Equal(t, JSON, Default("POST", "text/json"))
+
+	RegisterMIMEAlias("application/x-xml", MIMEXML)
+	defer UnregisterMIMEAlias("application/x-xml")
+	assert.Equal(t, XML, Default("POST", "application/x-xml"))
+
+	RegisterMIMEAlias("application/x-json", MIMEJSON)
+	UnregisterMIMEAlias("application/x-json")
+	assert.Equal(t, "application/x-json", CanonicalMIME("application/x-json"))
+}
+
+func TestBindingPlain(t *testing.T) {
+	assert.Equal(t, "plain", Plain.Name())
+
+	req := requestWithBody("POST", "/", "hello")
+	var s string
+	assert.Equal(t, nil, Plain.Bind(req, &s))
+	assert.Equal(t, "hello", s)
+
+	var b []byte
+	assert.Equal(t, nil, Plain.BindBody([]byte("hello"), &b))
+	assert.Equal(t, []byte("hello"), b)
+
+	var obj FooStruct
+	err := Plain.BindBody([]byte("hello"), &obj)
+	assert.Equal(t, "binding: plain binding requires a *string or a *[]byte, got *binding.FooStruct", err.Error())
+
+	req, _ = http.NewRequest("POST", "/", nil)
+	assert.NotEqual(t, nil, Plain.Bind(req, &s))
+}
+
+type fakeCBORBinding struct{}
+
+func (fakeCBORBinding) Name() string                          { return "cbor" }
+func (fakeCBORBinding) Bind(*http.Request, interface{}) error { return nil }
+
+func TestBindingRegister(t *testing.T) {
+	Register("application/cbor", fakeCBORBinding{})
+	defer Unregister("application/cbor")
+
+	assert.Equal(t, fakeCBORBinding{}, Default("POST", "application/cbor"))
+	assert.Equal(t, Form, Default("GET", "application/cbor"))
+	assert.Equal(t, JSON, Default("POST", MIMEJSON))
+
+	assert.PanicMatches(t, func() { Register("application/cbor", nil) }, "binding: Register binding is nil")
+
+	Register(MIMEJSON, fakeCBORBinding{})
+	Unregister(MIMEJSON)
+	assert.Equal(t, JSON, Default("POST", MIMEJSON))
 }
 
 func TestBindingJSONNilBody(t *testing.T) {
@@ -204,6 +260,22 @@ func TestBindingForm2(t *testing.T) {
 		"", "")
 }
 
+func TestBindingFormJSONTagFallback(t *testing.T) {
+	EnableFormJSONTagFallback = true
+	defer func() { EnableFormJSONTagFallback = false }()
+
+	var obj struct {
+		UserName string `json:"user_name" binding:"required"`
+		Age      int    `json:"age"`
+	}
+	req := requestWithBody("POST", "/", "user_name=gin&age=7")
+	req.Header.Add("Content-Type", MIMEPOSTForm)
+	err := Form.Bind(req, &obj)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "gin", obj.UserName)
+	assert.Equal(t, 7, obj.Age)
+}
+
 func TestBindingFormEmbeddedStruct(t *testing.T) {
 	testFormBindingEmbeddedStruct(t, "POST",
 		"/", "/",
@@ -358,6 +430,32 @@ func TestBindingQueryBoolFail(t *testing.T) {
 		"bool_foo=unused", "")
 }
 
+func TestBindingQueryNested(t *testing.T) {
+	var obj struct {
+		User struct {
+			Name    string `form:"name"`
+			Age     int    `form:"age"`
+			Address *struct {
+				City string `form:"city"`
+			} `form:"address"`
+		} `form:"user"`
+		Page int `form:"page,default=1"`
+	}
+	req := requestWithBody("GET", "/?user.name=x&user.age=3&user.address.city=paris", "")
+	err := Query.Bind(req, &obj)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "x", obj.User.Name)
+	assert.Equal(t, 3, obj.User.Age)
+	assert.Equal(t, "paris", obj.User.Address.City)
+	assert.Equal(t, 1, obj.Page)
+
+	// the bare keys still work for nested fields
+	req = requestWithBody("GET", "/?name=y&user.age=wrong", "")
+	err = Query.Bind(req, &obj)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, "y", obj.User.Name)
+}
+
 func TestBindingXML(t *testing.T) {
 	testBodyBinding(t,
 		XML, "xml",
@@ -623,6 +721,40 @@ func TestHeaderBinding(t *testing.T) {
 	assert.NotEqual(t, nil, err)
 }
 
+func TestHeaderBindingCaseInsensitive(t *testing.T) {
+	type tHeader struct {
+		MyHeader string `header:"x-my-header"`
+		Limit    int    `header:"LIMIT"`
+	}
+
+	var theader tHeader
+	req := requestWithBody("GET", "/", "")
+	req.Header.Add("X-My-Header", "value")
+	req.Header["limit"] = []string{"10"} // not canonical
+	assert.Equal(t, nil, Header.Bind(req, &theader))
+	assert.Equal(t, "value", theader.MyHeader)
+	assert.Equal(t, 10, theader.Limit)
+}
+
+func TestHeaderBindingRequired(t *testing.T) {
+	type tHeader struct {
+		Token string `header:"X-Token" binding:"required"`
+		Limit int    `header:"limit"`
+	}
+
+	var theader tHeader
+	req := requestWithBody("GET", "/", "")
+	req.Header.Add("X-Token", "secret")
+	assert.Equal(t, nil, Header.Bind(req, &theader))
+	assert.Equal(t, "secret", theader.Token)
+
+	req = requestWithBody("GET", "/", "")
+	req.Header.Add("limit", "1000")
+	err := Header.Bind(req, &tHeader{})
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, `required header "X-Token" is missing`, err.Error())
+}
+
 func TestUriBinding(t *testing.T) {
 	b := Uri
 	assert.Equal(t, "uri", b.Name())
@@ -644,6 +776,20 @@ func TestUriBinding(t *testing.T) {
 	assert.Equal(t, map[string]interface{}(nil), not.Name)
 }
 
+func TestRequestBinding(t *testing.T) {
+	b := Request
+	assert.Equal(t, "request", b.Name())
+
+	var obj struct {
+		Method string `req:"method"`
+		Path   string `req:"path"`
+	}
+	m := map[string][]string{"method": {"GET"}, "path": {"/users"}}
+	assert.Equal(t, nil, b.BindUri(m, &obj))
+	assert.Equal(t, "GET", obj.Method)
+	assert.Equal(t, "/users", obj.Path)
+}
+
 func TestUriInnerBinding(t *testing.T) {
 	type Tag struct {
 		Name string `uri:"name"`
@@ -7,17 +7,21 @@ package binding
 import (
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/manucorporat/gin-diet/internal/bytesconv"
-	"github.com/manucorporat/gin-diet/internal/json"
 )
 
 var errUnknownType = errors.New("unknown type")
 
+// EnableFormJSONTagFallback makes the form mapping read the `json` tag of the
+// fields having no `form` tag, so the structs tagged for JSON can be bound from forms too.
+var EnableFormJSONTagFallback = false
+
 func mapUri(ptr interface{}, m map[string][]string) error {
 	return mapFormByTag(ptr, m, "uri")
 }
@@ -52,7 +56,7 @@ func mappingByPtr(ptr interface{}, setter setter, tag string) error {
 }
 
 func mapping(value reflect.Value, field reflect.StructField, setter setter, tag string) (bool, error) {
-	if field.Tag.Get(tag) == "-" { // just ignoring this field
+	if lookupTag(field, tag) == "-" { // just ignoring this field
 		return false, nil
 	}
 
@@ -88,6 +92,14 @@ func mapping(value reflect.Value, field reflect.StructField, setter setter, tag
 	if vKind == reflect.Struct {
 		tValue := value.Type()
 
+		if field.Name != "" && !field.Anonymous {
+			name, _ := head(lookupTag(field, tag), ",")
+			if name == "" {
+				name = field.Name
+			}
+			setter = prefixedSetter{setter: setter, prefix: name + "."}
+		}
+
 		var isSetted bool
 		for i := 0; i < value.NumField(); i++ {
 			sf := tValue.Field(i)
@@ -105,16 +117,45 @@ func mapping(value reflect.Value, field reflect.StructField, setter setter, tag
 	return false, nil
 }
 
+// prefixedSetter lets the fields of a nested struct be set by dotted keys,
+// e.g. "user.name" for the Name field of a User field, before trying the bare key.
+type prefixedSetter struct {
+	setter setter
+	prefix string
+}
+
+var _ setter = prefixedSetter{}
+
+func (s prefixedSetter) TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (isSetted bool, err error) {
+	// the default value only applies when neither key is present
+	isSetted, err = s.setter.TrySet(value, field, s.prefix+key, setOptions{})
+	if isSetted || err != nil {
+		return isSetted, err
+	}
+	return s.setter.TrySet(value, field, key, opt)
+}
+
+// lookupTag returns the tag of the field, or its json tag when enabled by
+// EnableFormJSONTagFallback and the field has no form tag.
+func lookupTag(field reflect.StructField, tag string) string {
+	value, ok := field.Tag.Lookup(tag)
+	if !ok && tag == "form" && EnableFormJSONTagFallback {
+		return field.Tag.Get("json")
+	}
+	return value
+}
+
 type setOptions struct {
 	isDefaultExists bool
 	defaultValue    string
+	isRequired      bool
 }
 
 func tryToSetValue(value reflect.Value, field reflect.StructField, setter setter, tag string) (bool, error) {
 	var tagValue string
 	var setOpt setOptions
 
-	tagValue = field.Tag.Get(tag)
+	tagValue = lookupTag(field, tag)
 	tagValue, opts := head(tagValue, ",")
 
 	if tagValue == "" { // default value is FieldName
@@ -129,14 +170,36 @@ func tryToSetValue(value reflect.Value, field reflect.StructField, setter setter
 		opt, opts = head(opts, ",")
 
 		if k, v := head(opt, "="); k == "default" {
-			setOpt.isDefaultExists = true
-			setOpt.defaultValue = v
+			setOpt.isDefaultExists, setOpt.defaultValue = defaultValue(v)
+		}
+	}
+
+	for rules := field.Tag.Get("binding"); len(rules) > 0; {
+		var rule string
+		rule, rules = head(rules, ",")
+		if rule == "required" {
+			setOpt.isRequired = true
 		}
 	}
 
 	return setter.TrySet(value, field, tagValue, setOpt)
 }
 
+// defaultValue resolves the value of a default= tag option: "$NAME" reads the
+// environment variable NAME, there is no default when it is not set, and "$$"
+// escapes a literal "$". The environment is read at every binding, so only use
+// it for trusted internal endpoints, a client can't set it but learns its value.
+func defaultValue(v string) (bool, string) {
+	if !strings.HasPrefix(v, "$") {
+		return true, v
+	}
+	if strings.HasPrefix(v, "$$") {
+		return true, v[1:]
+	}
+	env, ok := os.LookupEnv(v[1:])
+	return ok, env
+}
+
 func setByForm(value reflect.Value, field reflect.StructField, form map[string][]string, tagValue string, opt setOptions) (isSetted bool, err error) {
 	vs, ok := form[tagValue]
 	if !ok && !opt.isDefaultExists {
@@ -209,15 +272,19 @@ func setWithProperType(val string, value reflect.Value, field reflect.StructFiel
 		case time.Time:
 			return setTimeField(val, field, value)
 		}
-		return json.Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
+		return unmarshalJSON(bytesconv.StringToBytes(val), value.Addr().Interface())
 	case reflect.Map:
-		return json.Unmarshal(bytesconv.StringToBytes(val), value.Addr().Interface())
+		return unmarshalJSON(bytesconv.StringToBytes(val), value.Addr().Interface())
 	default:
 		return errUnknownType
 	}
 	return nil
 }
 
+func unmarshalJSON(data []byte, v interface{}) error {
+	return JSONUnmarshal(data, v)
+}
+
 func setIntField(val string, bitSize int, field reflect.Value) error {
 	if val == "" {
 		val = "0"
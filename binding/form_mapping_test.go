@@ -5,6 +5,7 @@
 package binding
 
 import (
+	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -71,6 +72,58 @@ func TestMappingDefault(t *testing.T) {
 	assert.Equal(t, [1]int{9}, s.Array)
 }
 
+func TestMappingDefaultEnv(t *testing.T) {
+	os.Setenv("GIN_TEST_DEFAULT_PORT", "8080")
+	defer os.Unsetenv("GIN_TEST_DEFAULT_PORT")
+
+	var s struct {
+		Port    int    `form:"port,default=$GIN_TEST_DEFAULT_PORT"`
+		Missing int    `form:"missing,default=$GIN_TEST_DEFAULT_MISSING"`
+		Dollar  string `form:"dollar,default=$$5"`
+	}
+	err := mappingByPtr(&s, formSource{}, "form")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, 0, s.Missing)
+	assert.Equal(t, "$5", s.Dollar)
+
+	err = mappingByPtr(&s, formSource{"port": {"9000"}}, "form")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 9000, s.Port)
+}
+
+func TestMappingJSONTagFallback(t *testing.T) {
+	var s struct {
+		Name    string `json:"name,omitempty"`
+		Age     int    `json:"age" form:"years"`
+		Ignored string `json:"-"`
+		Plain   string
+	}
+	form := formSource{"name": {"gin"}, "age": {"1"}, "years": {"7"}, "Ignored": {"x"}, "Plain": {"p"}}
+
+	err := mappingByPtr(&s, form, "form")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", s.Name)
+	assert.Equal(t, "x", s.Ignored)
+
+	EnableFormJSONTagFallback = true
+	defer func() { EnableFormJSONTagFallback = false }()
+
+	s.Ignored = ""
+	err = mappingByPtr(&s, form, "form")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "gin", s.Name)
+	assert.Equal(t, 7, s.Age)
+	assert.Equal(t, "", s.Ignored)
+	assert.Equal(t, "p", s.Plain)
+
+	// the uri mapping never reads the json tag
+	s.Name = ""
+	err = mappingByPtr(&s, formSource{"name": {"gin"}}, "uri")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", s.Name)
+}
+
 func TestMappingSkipField(t *testing.T) {
 	var s struct {
 		A int
@@ -1,9 +1,11 @@
 package binding
 
 import (
+	"fmt"
 	"net/http"
 	"net/textproto"
 	"reflect"
+	"strings"
 )
 
 type headerBinding struct{}
@@ -30,5 +32,18 @@ type headerSource map[string][]string
 var _ setter = headerSource(nil)
 
 func (hs headerSource) TrySet(value reflect.Value, field reflect.StructField, tagValue string, opt setOptions) (isSetted bool, err error) {
-	return setByForm(value, field, hs, textproto.CanonicalMIMEHeaderKey(tagValue), opt)
+	key := textproto.CanonicalMIMEHeaderKey(tagValue)
+	if _, ok := hs[key]; !ok {
+		// headers set directly in the map may not be canonical
+		for k := range hs {
+			if strings.EqualFold(k, key) {
+				key = k
+				break
+			}
+		}
+	}
+	if _, ok := hs[key]; !ok && opt.isRequired && !opt.isDefaultExists {
+		return false, fmt.Errorf("required header %q is missing", key)
+	}
+	return setByForm(value, field, hs, key, opt)
 }
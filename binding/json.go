@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/manucorporat/gin-diet/internal/json"
@@ -24,6 +25,22 @@ var EnableDecoderUseNumber = false
 // keys which do not match any non-ignored, exported fields in the destination.
 var EnableDecoderDisallowUnknownFields = false
 
+// DecoderMaxDepth limits how deeply the objects and arrays of a JSON body may be
+// nested, deeper payloads are rejected while they are read, before the decoder
+// recurses into them. 0 means no limit.
+var DecoderMaxDepth = 0
+
+// DecoderMaxArrayLength limits how many elements a JSON array of a request body
+// may hold, so a huge array can not be bound into a slice. 0 means no limit.
+var DecoderMaxArrayLength = 0
+
+// JSONUnmarshal decodes the JSON bodies and the JSON values of form fields, it defaults
+// to encoding/json and can be replaced once at init by a faster library, e.g.
+// binding.JSONUnmarshal = jsoniter.Unmarshal. The bodies are decoded by an encoding/json
+// Decoder instead when EnableDecoderUseNumber or EnableDecoderDisallowUnknownFields is set,
+// the decoder limits always apply.
+var JSONUnmarshal = json.Unmarshal
+
 type jsonBinding struct{}
 
 func (jsonBinding) Name() string {
@@ -42,6 +59,23 @@ func (jsonBinding) BindBody(body []byte, obj interface{}) error {
 }
 
 func decodeJSON(r io.Reader, obj interface{}) error {
+	if DecoderMaxDepth > 0 || DecoderMaxArrayLength > 0 {
+		r = &jsonLimitReader{r: r, maxDepth: DecoderMaxDepth, maxArrayLength: DecoderMaxArrayLength}
+	}
+	if !EnableDecoderUseNumber && !EnableDecoderDisallowUnknownFields {
+		body, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		if len(body) == 0 {
+			// as reported by the Decoder
+			return io.EOF
+		}
+		if err := JSONUnmarshal(body, obj); err != nil {
+			return err
+		}
+		return validate(obj)
+	}
 	decoder := json.NewDecoder(r)
 	if EnableDecoderUseNumber {
 		decoder.UseNumber()
@@ -54,3 +88,73 @@ func decodeJSON(r io.Reader, obj interface{}) error {
 	}
 	return validate(obj)
 }
+
+// jsonLimitReader scans the JSON read through it and fails as soon as it is nested
+// deeper than maxDepth or an array holds more than maxArrayLength elements.
+type jsonLimitReader struct {
+	r              io.Reader
+	maxDepth       int
+	maxArrayLength int
+	// lengths holds an element count for each open array and -1 for each open object
+	lengths     []int
+	expectValue bool
+	inString    bool
+	escaped     bool
+}
+
+func (l *jsonLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	for _, c := range p[:n] {
+		if l.inString {
+			switch {
+			case l.escaped:
+				l.escaped = false
+			case c == '\\':
+				l.escaped = true
+			case c == '"':
+				l.inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case ',':
+			l.expectValue = len(l.lengths) > 0 && l.lengths[len(l.lengths)-1] >= 0
+			continue
+		case ']', '}':
+			if len(l.lengths) > 0 {
+				l.lengths = l.lengths[:len(l.lengths)-1]
+			}
+			l.expectValue = false
+			continue
+		}
+
+		// c starts or continues a value, count it in its array
+		if l.expectValue {
+			l.expectValue = false
+			top := len(l.lengths) - 1
+			l.lengths[top]++
+			if l.maxArrayLength > 0 && l.lengths[top] > l.maxArrayLength {
+				return 0, fmt.Errorf("json: array length exceeds the limit of %d", l.maxArrayLength)
+			}
+		}
+
+		switch c {
+		case '"':
+			l.inString = true
+		case '[', '{':
+			if l.maxDepth > 0 && len(l.lengths) >= l.maxDepth {
+				return 0, fmt.Errorf("json: nesting depth exceeds the limit of %d", l.maxDepth)
+			}
+			if c == '[' {
+				l.lengths = append(l.lengths, 0)
+				l.expectValue = true
+			} else {
+				l.lengths = append(l.lengths, -1)
+			}
+		}
+	}
+	return n, err
+}
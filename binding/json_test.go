@@ -5,6 +5,9 @@
 package binding
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/go-playground/assert"
@@ -18,3 +21,71 @@ func TestJSONBindingBindBody(t *testing.T) {
 	assert.Equal(t, err, nil)
 	assert.Equal(t, "FOO", s.Foo)
 }
+
+func TestJSONBindingCustomUnmarshal(t *testing.T) {
+	JSONUnmarshal = func(data []byte, v interface{}) error {
+		return json.Unmarshal(bytes.ToUpper(data), v)
+	}
+	defer func() {
+		JSONUnmarshal = json.Unmarshal
+	}()
+
+	var s struct {
+		Foo string `json:"FOO"`
+	}
+	err := jsonBinding{}.BindBody([]byte(`{"foo": "sentinel"}`), &s)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "SENTINEL", s.Foo)
+
+	var f struct {
+		M map[string]string `form:"m"`
+	}
+	err = mapForm(&f, map[string][]string{"m": {`{"foo": "sentinel"}`}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]string{"FOO": "SENTINEL"}, f.M)
+}
+
+func TestJSONBindingMaxDepth(t *testing.T) {
+	DecoderMaxDepth = 3
+	defer func() {
+		DecoderMaxDepth = 0
+	}()
+
+	var obj interface{}
+	err := jsonBinding{}.BindBody([]byte(`{"a": [{"b": "[[[{{{\"]]]"}]}`), &obj)
+	assert.Equal(t, nil, err)
+
+	deep := strings.Repeat("[", 100000) + strings.Repeat("]", 100000)
+	err = jsonBinding{}.BindBody([]byte(deep), &obj)
+	assert.Equal(t, "json: nesting depth exceeds the limit of 3", err.Error())
+
+	err = jsonBinding{}.BindBody([]byte(`{"a": [{"b": [1]}]}`), &obj)
+	assert.Equal(t, "json: nesting depth exceeds the limit of 3", err.Error())
+}
+
+func TestJSONBindingMaxArrayLength(t *testing.T) {
+	DecoderMaxArrayLength = 3
+	defer func() {
+		DecoderMaxArrayLength = 0
+	}()
+
+	var obj struct {
+		IDs   []int               `json:"ids"`
+		Names map[string][]string `json:"names"`
+	}
+	err := jsonBinding{}.BindBody([]byte(`{"ids": [1, 22, 333], "names": {"a": ["x,y", "]"], "b": [], "c": [""], "d": ["1"]}}`), &obj)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []int{1, 22, 333}, obj.IDs)
+	assert.Equal(t, []string{"x,y", "]"}, obj.Names["a"])
+
+	err = jsonBinding{}.BindBody([]byte(`{"ids": [1, 2, 3, 4]}`), &obj)
+	assert.Equal(t, "json: array length exceeds the limit of 3", err.Error())
+
+	err = jsonBinding{}.BindBody([]byte(`{"names": {"a": [[], [[1, 2, 3]], {}, null]}}`), &obj)
+	assert.Equal(t, "json: array length exceeds the limit of 3", err.Error())
+
+	var matrix [][]int
+	err = jsonBinding{}.BindBody([]byte(`[[1, 2, 3], [4, 5, 6], [7, 8, 9]]`), &matrix)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(matrix))
+}
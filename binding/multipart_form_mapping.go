@@ -9,6 +9,9 @@ import (
 	"mime/multipart"
 	"net/http"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type multipartRequest http.Request
@@ -20,10 +23,81 @@ func (r *multipartRequest) TrySet(value reflect.Value, field reflect.StructField
 	if files := r.MultipartForm.File[key]; len(files) != 0 {
 		return setByMultipartFormFile(value, field, files)
 	}
+	if isStructSlice(value.Type()) {
+		if indexes := r.indexes(key); len(indexes) != 0 {
+			return r.setStructSlice(value, key, indexes)
+		}
+	}
 
 	return setByForm(value, field, r.MultipartForm.Value, key, opt)
 }
 
+var fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+
+func isStructSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	if t = t.Elem(); t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != fileHeaderType
+}
+
+// indexes returns the sorted indexes of the "key[N][field]" values and files.
+func (r *multipartRequest) indexes(key string) []int {
+	seen := make(map[int]bool)
+	collect := func(name string) {
+		rest := strings.TrimPrefix(name, key+"[")
+		end := strings.IndexByte(rest, ']')
+		if rest == name || end <= 0 || !strings.HasPrefix(rest[end+1:], "[") {
+			return
+		}
+		if i, err := strconv.Atoi(rest[:end]); err == nil && i >= 0 {
+			seen[i] = true
+		}
+	}
+	for name := range r.MultipartForm.Value {
+		collect(name)
+	}
+	for name := range r.MultipartForm.File {
+		collect(name)
+	}
+
+	indexes := make([]int, 0, len(seen))
+	for i := range seen {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// setStructSlice binds the "key[N][field]" values and files into a slice of structs,
+// one element by index in ascending order, so the gaps between indexes are skipped.
+func (r *multipartRequest) setStructSlice(value reflect.Value, key string, indexes []int) (bool, error) {
+	slice := reflect.MakeSlice(value.Type(), len(indexes), len(indexes))
+	for n, i := range indexes {
+		setter := indexedSetter{setter: r, prefix: key + "[" + strconv.Itoa(i) + "]"}
+		if _, err := mapping(slice.Index(n), emptyField, setter, "form"); err != nil {
+			return false, err
+		}
+	}
+	value.Set(slice)
+	return true, nil
+}
+
+// indexedSetter sets the fields of a slice element by their "prefix[field]" key.
+type indexedSetter struct {
+	setter setter
+	prefix string
+}
+
+var _ setter = indexedSetter{}
+
+func (s indexedSetter) TrySet(value reflect.Value, field reflect.StructField, key string, opt setOptions) (isSetted bool, err error) {
+	return s.setter.TrySet(value, field, s.prefix+"["+key+"]", opt)
+}
+
 func setByMultipartFormFile(value reflect.Value, field reflect.StructField, files []*multipart.FileHeader) (isSetted bool, err error) {
 	switch value.Kind() {
 	case reflect.Ptr:
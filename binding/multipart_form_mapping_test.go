@@ -94,6 +94,39 @@ func TestFormMultipartBindingBindError(t *testing.T) {
 	}
 }
 
+func TestFormMultipartBindingBindStructSlice(t *testing.T) {
+	var s struct {
+		Items []struct {
+			Name string                `form:"name"`
+			File *multipart.FileHeader `form:"file"`
+		} `form:"items"`
+		Ptrs []*struct {
+			Name string `form:"name"`
+		} `form:"ptrs"`
+	}
+	files := []testFile{
+		{"items[0][file]", "file1", []byte("hello")},
+		{"items[1][file]", "file2", []byte("world")},
+	}
+	values := map[string]string{
+		"items[0][name]": "first",
+		"items[1][name]": "second",
+		"ptrs[3][name]":  "third",
+	}
+
+	req := createRequestMultipart(t, values, files...)
+	err := FormMultipart.Bind(req, &s)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, 2, len(s.Items))
+	assert.Equal(t, "first", s.Items[0].Name)
+	assertMultipartFileHeader(t, s.Items[0].File, files[0])
+	assert.Equal(t, "second", s.Items[1].Name)
+	assertMultipartFileHeader(t, s.Items[1].File, files[1])
+	assert.Equal(t, 1, len(s.Ptrs))
+	assert.Equal(t, "third", s.Ptrs[0].Name)
+}
+
 type testFile struct {
 	Fieldname string
 	Filename  string
@@ -101,9 +134,16 @@ type testFile struct {
 }
 
 func createRequestMultipartFiles(t *testing.T, files ...testFile) *http.Request {
+	return createRequestMultipart(t, nil, files...)
+}
+
+func createRequestMultipart(t *testing.T, values map[string]string, files ...testFile) *http.Request {
 	var body bytes.Buffer
 
 	mw := multipart.NewWriter(&body)
+	for name, value := range values {
+		assert.Equal(t, nil, mw.WriteField(name, value))
+	}
 	for _, file := range files {
 		fw, err := mw.CreateFormFile(file.Fieldname, file.Filename)
 		assert.Equal(t, nil, err)
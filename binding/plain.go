@@ -0,0 +1,44 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+type plainBinding struct{}
+
+func (plainBinding) Name() string {
+	return "plain"
+}
+
+func (plainBinding) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return fmt.Errorf("invalid request")
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	return decodePlain(body, obj)
+}
+
+func (plainBinding) BindBody(body []byte, obj interface{}) error {
+	return decodePlain(append([]byte(nil), body...), obj)
+}
+
+func decodePlain(body []byte, obj interface{}) error {
+	switch ptr := obj.(type) {
+	case *string:
+		*ptr = string(body)
+	case *[]byte:
+		*ptr = body
+	default:
+		return fmt.Errorf("binding: plain binding requires a *string or a *[]byte, got %T", obj)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package binding
+
+// requestBinding binds request metadata, such as the method or the path,
+// to the fields tagged with `req:"method"`, `req:"path"` or `req:"remote_ip"`.
+type requestBinding struct{}
+
+func (requestBinding) Name() string {
+	return "request"
+}
+
+func (requestBinding) BindUri(m map[string][]string, obj interface{}) error {
+	if err := mapFormByTag(obj, m, "req"); err != nil {
+		return err
+	}
+	return validate(obj)
+}
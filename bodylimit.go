@@ -0,0 +1,115 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrBodyTooLarge is returned when reading a request body beyond the MaxBodyBytes limit.
+// Bind and the other Must bindings abort with a 413 code for it.
+var ErrBodyTooLarge = errors.New("http: request body too large")
+
+// MaxBodyBytes returns a middleware limiting the request bodies read by the handlers
+// after it to n bytes, the reads beyond the limit fail with ErrBodyTooLarge.
+// It complements Engine.MaxMultipartMemory for the bodies that are not multipart forms.
+func MaxBodyBytes(n int64) HandlerFunc {
+	return func(c *Context) {
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = &maxBodyReader{
+				ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, n),
+				limit:      n,
+			}
+		}
+		c.Next()
+	}
+}
+
+// maxBodyReader turns the error of http.MaxBytesReader into ErrBodyTooLarge.
+type maxBodyReader struct {
+	io.ReadCloser
+	read  int64
+	limit int64
+}
+
+func (r *maxBodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if err != nil && err != io.EOF && r.read >= r.limit {
+		err = ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// DecompressRequest returns a middleware decompressing the gzip and deflate request bodies,
+// as told by their Content-Encoding header, for the handlers after it. The decompressed body
+// is limited to maxDecompressed bytes and the reads beyond fail with ErrBodyTooLarge, so a small
+// compressed body can not expand without bound. A malformed gzip body is aborted with 400 and
+// the other encodings with 415.
+func DecompressRequest(maxDecompressed int64) HandlerFunc {
+	return func(c *Context) {
+		var zr io.ReadCloser
+		switch encoding := strings.ToLower(strings.TrimSpace(c.requestHeader("Content-Encoding"))); encoding {
+		case "", "identity":
+			c.Next()
+			return
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				c.AbortWithError(http.StatusBadRequest, err) // nolint: errcheck
+				return
+			}
+			zr = gz
+		case "deflate":
+			zr = flate.NewReader(c.Request.Body)
+		default:
+			c.AbortWithStatus(http.StatusUnsupportedMediaType)
+			return
+		}
+
+		c.Request.Body = &decompressReader{
+			ReadCloser: zr,
+			body:       c.Request.Body,
+			limit:      maxDecompressed,
+		}
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		c.Next()
+	}
+}
+
+// decompressReader reads a decompressed body up to limit bytes.
+type decompressReader struct {
+	io.ReadCloser
+	body  io.Closer
+	read  int64
+	limit int64
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	if r.read > r.limit {
+		return 0, ErrBodyTooLarge
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if r.read > r.limit {
+		return n - int(r.read-r.limit), ErrBodyTooLarge
+	}
+	return n, err
+}
+
+func (r *decompressReader) Close() error {
+	err := r.ReadCloser.Close()
+	if berr := r.body.Close(); err == nil {
+		err = berr
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestMaxBodyBytes(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	var bindErr error
+	var bound payload
+	router := New()
+	router.Use(MaxBodyBytes(32))
+	router.POST("/should", func(c *Context) {
+		bound = payload{}
+		bindErr = c.ShouldBindJSON(&bound)
+	})
+	router.POST("/must", func(c *Context) {
+		if c.BindJSON(&bound) == nil {
+			c.Status(http.StatusNoContent)
+		}
+	})
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", MIMEJSON)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	post("/should", `{"name":"gin"}`)
+	assert.Equal(t, nil, bindErr)
+	assert.Equal(t, "gin", bound.Name)
+
+	post("/should", `{"name":"`+strings.Repeat("a", 64)+`"}`)
+	assert.Equal(t, ErrBodyTooLarge, bindErr)
+
+	w := post("/must", `{"name":"gin"}`)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = post("/must", `{"name":"`+strings.Repeat("a", 64)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestDecompressRequest(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	router := New()
+	router.Use(DecompressRequest(64))
+	router.POST("/", func(c *Context) {
+		var bound payload
+		if c.BindJSON(&bound) == nil {
+			c.String(http.StatusOK, bound.Name)
+		}
+	})
+
+	post := func(encoding string, body []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", MIMEJSON)
+		req.Header.Set("Content-Encoding", encoding)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	compress := func(data string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte(data))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, gz.Close())
+		return buf.Bytes()
+	}
+
+	w := post("gzip", compress(`{"name":"gin"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gin", w.Body.String())
+
+	w = post("", []byte(`{"name":"plain"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "plain", w.Body.String())
+
+	// a few compressed bytes expanding beyond the limit
+	bomb := compress(`{"name":"` + strings.Repeat("a", 1<<20) + `"}`)
+	assert.Equal(t, true, len(bomb) < 4096)
+	w = post("gzip", bomb)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = post("gzip", []byte("not gzip"))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = post("br", []byte("{}"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
@@ -5,19 +5,29 @@
 package gin
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/x509"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
+	"mime"
 	"mime/multipart"
 	"net"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"os"
+	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/manucorporat/gin-diet/binding"
 	"github.com/manucorporat/gin-diet/render"
@@ -32,11 +42,24 @@ const (
 	MIMEPlain             = binding.MIMEPlain
 	MIMEPOSTForm          = binding.MIMEPOSTForm
 	MIMEMultipartPOSTForm = binding.MIMEMultipartPOSTForm
+	MIMEProblemJSON       = "application/problem+json"
+	MIMEProblemXML        = "application/problem+xml"
 	BodyBytesKey          = "_gin-gonic/gin/bodybyteskey"
 )
 
 const abortIndex int8 = math.MaxInt8 / 2
 
+// CacheOption is a Cache-Control directive added by Context.CacheControl.
+type CacheOption string
+
+// Cache-Control directives accepted by Context.CacheControl.
+const (
+	CachePublic    CacheOption = "public"
+	CachePrivate   CacheOption = "private"
+	CacheNoStore   CacheOption = "no-store"
+	CacheImmutable CacheOption = "immutable"
+)
+
 // Context is the most important part of gin. It allows us to pass variables between middleware,
 // manage the flow, validate the JSON of a request and render a JSON response for example.
 type Context struct {
@@ -73,6 +96,13 @@ type Context struct {
 	// SameSite allows a server to define a cookie attribute making it impossible for
 	// the browser to send this cookie along with cross-site requests.
 	sameSite http.SameSite
+
+	// cleanups are the functions registered with OnCleanup, kept across HandleContext.
+	cleanups []func()
+
+	// deadline reports whether Deadline, Done and Err follow the request context,
+	// see Engine.HandlerTimeout. It is cleared by Copy.
+	deadline bool
 }
 
 /************************************/
@@ -95,12 +125,17 @@ func (c *Context) reset() {
 
 // Copy returns a copy of the current context that can be safely used outside the request's scope.
 // This has to be used when the context has to be passed to a goroutine.
+// The copy is detached from the Engine.HandlerTimeout deadline: its Deadline, Done and Err
+// report no deadline, as the request context is cancelled once the handlers return.
 func (c *Context) Copy() *Context {
 	var cp = *c
 	cp.writermem.ResponseWriter = nil
+	cp.writermem.beforeWrite = nil
 	cp.Writer = &cp.writermem
 	cp.index = abortIndex
 	cp.handlers = nil
+	cp.cleanups = nil
+	cp.deadline = false
 	cp.Keys = map[string]interface{}{}
 	for k, v := range c.Keys {
 		cp.Keys[k] = v
@@ -132,6 +167,11 @@ func (c *Context) Handler() HandlerFunc {
 	return c.handlers.Last()
 }
 
+// Engine returns the engine that handles the request, e.g. to list its routes from a middleware.
+func (c *Context) Engine() *Engine {
+	return c.engine
+}
+
 // FullPath returns a matched route full path. For not found routes
 // returns an empty string.
 //     router.GET("/user/:id", func(c *gin.Context) {
@@ -141,6 +181,16 @@ func (c *Context) FullPath() string {
 	return c.fullPath
 }
 
+// RouteMeta returns the value attached to the matched route with WithMeta for
+// the given key, ie: (value, true). If the value does not exist it returns (nil, false).
+//     router.GET("/admin", handler).WithMeta("scopes", []string{"admin"})
+//     // in a middleware
+//     scopes, _ := c.RouteMeta("scopes")
+func (c *Context) RouteMeta(key string) (value interface{}, exists bool) {
+	value, exists = c.engine.routesMeta[routeKey{method: c.Request.Method, path: c.fullPath}][key]
+	return
+}
+
 /************************************/
 /*********** FLOW CONTROL ***********/
 /************************************/
@@ -156,6 +206,28 @@ func (c *Context) Next() {
 	}
 }
 
+// OnCleanup registers a function called once the handlers chain returns, whether
+// it was aborted, panicked or not. The functions are called in the reverse order of registration.
+func (c *Context) OnCleanup(fn func()) {
+	c.cleanups = append(c.cleanups, fn)
+}
+
+// OnResponse registers a function called just before the response header is written,
+// so it can still change the headers and the status. The functions are called in the
+// reverse order of registration and not at all when the response was already written.
+func (c *Context) OnResponse(fn func(c *Context)) {
+	c.writermem.beforeWrite = append(c.writermem.beforeWrite, func() { fn(c) })
+}
+
+func (c *Context) runCleanups() {
+	// dropped first, so the functions are released even when one of them panics
+	cleanups := c.cleanups
+	c.cleanups = nil
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
 // IsAborted returns true if the current context was aborted.
 func (c *Context) IsAborted() bool {
 	return c.index >= abortIndex
@@ -172,9 +244,9 @@ func (c *Context) Abort() {
 // AbortWithStatus calls `Abort()` and writes the headers with the specified status code.
 // For example, a failed attempt to authenticate a request could use: context.AbortWithStatus(401).
 func (c *Context) AbortWithStatus(code int) {
+	c.Abort()
 	c.Status(code)
 	c.Writer.WriteHeaderNow()
-	c.Abort()
 }
 
 // AbortWithStatusJSON calls `Abort()` and then `JSON` internally.
@@ -185,6 +257,22 @@ func (c *Context) AbortWithStatusJSON(code int, jsonObj interface{}) {
 	c.JSON(code, jsonObj)
 }
 
+// AbortWithStatusJSONIndented calls `Abort()` and then `IndentedJSON` internally.
+// This method stops the chain, writes the status code and return a pretty-printed JSON body.
+// It also sets the Content-Type as "application/json".
+func (c *Context) AbortWithStatusJSONIndented(code int, jsonObj interface{}) {
+	c.Abort()
+	c.IndentedJSON(code, jsonObj)
+}
+
+// AbortWithStatusXML calls `Abort()` and then `XML` internally.
+// This method stops the chain, writes the status code and return a XML body.
+// It also sets the Content-Type as "application/xml".
+func (c *Context) AbortWithStatusXML(code int, xmlObj interface{}) {
+	c.Abort()
+	c.XML(code, xmlObj)
+}
+
 // AbortWithError calls `AbortWithStatus()` and `Error()` internally.
 // This method stops the chain, writes the status code and pushes the specified error to `c.Errors`.
 // See Context.Error() for more details.
@@ -193,6 +281,60 @@ func (c *Context) AbortWithError(code int, err error) *Error {
 	return c.Error(err)
 }
 
+// Shadow mirrors a copy of the current request to the given path, which is dispatched
+// through the engine in a goroutine and its response discarded, e.g. to try a new handler
+// with real traffic. The body is buffered, so it can still be read by the current handlers.
+// Engine.WaitShadowRequests waits for the shadow requests in flight.
+func (c *Context) Shadow(path string) {
+	var body []byte
+	if cb, ok := c.Get(BodyBytesKey); ok {
+		body, _ = cb.([]byte)
+	}
+	if body == nil && c.Request.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(c.Request.Body); err != nil {
+			c.Error(err) // nolint: errcheck
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	req := c.Request.Clone(context.Background())
+	req.URL.Path = path
+	req.URL.RawPath = ""
+	req.RequestURI = req.URL.RequestURI()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+
+	// the context is reused once the chain ends, the goroutine must not read it
+	engine, debug := c.engine, IsDebugging()
+	engine.shadows.Add(1)
+	go func() {
+		defer engine.shadows.Done()
+		defer func() {
+			if err := recover(); err != nil && debug {
+				writeDebug("[WARNING] shadow request to %s panicked: %v", path, err)
+			}
+		}()
+		engine.ServeHTTP(&discardResponseWriter{header: http.Header{}}, req)
+	}()
+}
+
+// discardResponseWriter is the http.ResponseWriter of the shadow requests.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
+
 /************************************/
 /********* ERROR MANAGEMENT *********/
 /************************************/
@@ -219,6 +361,25 @@ func (c *Context) Error(err error) *Error {
 	return parsedError
 }
 
+// SafeCall runs fn and returns its error. A panic in fn is recovered and returned as an
+// error too, so a failing sub-operation can be handled as a soft error instead of
+// failing the whole request. http.ErrAbortHandler is not recovered.
+func (c *Context) SafeCall(fn func() error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			if e, ok := rec.(error); ok {
+				err = fmt.Errorf("panic: %w", e)
+			} else {
+				err = fmt.Errorf("panic: %v", rec)
+			}
+		}
+	}()
+	return fn()
+}
+
 /************************************/
 /******** METADATA MANAGEMENT********/
 /************************************/
@@ -292,6 +453,22 @@ func (c *Context) GetInt64(key string) (i64 int64) {
 	return
 }
 
+// GetUint returns the value associated with the key as an unsigned integer.
+func (c *Context) GetUint(key string) (ui uint) {
+	if val, ok := c.Get(key); ok && val != nil {
+		ui, _ = val.(uint)
+	}
+	return
+}
+
+// GetUint64 returns the value associated with the key as an unsigned integer.
+func (c *Context) GetUint64(key string) (ui64 uint64) {
+	if val, ok := c.Get(key); ok && val != nil {
+		ui64, _ = val.(uint64)
+	}
+	return
+}
+
 // GetFloat64 returns the value associated with the key as a float64.
 func (c *Context) GetFloat64(key string) (f64 float64) {
 	if val, ok := c.Get(key); ok && val != nil {
@@ -348,6 +525,22 @@ func (c *Context) GetStringMapStringSlice(key string) (smss map[string][]string)
 	return
 }
 
+// GetStringMapInt returns the value associated with the key as a map of integers.
+func (c *Context) GetStringMapInt(key string) (smi map[string]int) {
+	if val, ok := c.Get(key); ok && val != nil {
+		smi, _ = val.(map[string]int)
+	}
+	return
+}
+
+// GetStringMapInt64 returns the value associated with the key as a map of integers.
+func (c *Context) GetStringMapInt64(key string) (smi64 map[string]int64) {
+	if val, ok := c.Get(key); ok && val != nil {
+		smi64, _ = val.(map[string]int64)
+	}
+	return
+}
+
 /************************************/
 /************ INPUT DATA ************/
 /************************************/
@@ -404,6 +597,47 @@ func (c *Context) GetQuery(key string) (string, bool) {
 	return "", false
 }
 
+// QueryInt returns the keyed url query value parsed as an int, it reports
+// false when the key is missing or the value is not a valid int.
+//     GET /?page=2&size=big
+//     (2, true) == c.QueryInt("page")
+//     (0, false) == c.QueryInt("size")
+//     (0, false) == c.QueryInt("id")
+func (c *Context) QueryInt(key string) (int, bool) {
+	value, ok := c.GetQuery(key)
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// DefaultQueryInt returns the keyed url query value parsed as an int if it is
+// valid, otherwise it returns the specified defaultValue.
+func (c *Context) DefaultQueryInt(key string, defaultValue int) int {
+	if i, ok := c.QueryInt(key); ok {
+		return i
+	}
+	return defaultValue
+}
+
+// QueryBool returns the keyed url query value parsed as a bool, accepting the values
+// of strconv.ParseBool, it reports false when the key is missing or the value is invalid.
+func (c *Context) QueryBool(key string) (bool, bool) {
+	value, ok := c.GetQuery(key)
+	if !ok {
+		return false, false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, false
+	}
+	return b, true
+}
+
 // QueryArray returns a slice of strings for a given query key.
 // The length of the slice depends on the number of params with the given key.
 func (c *Context) QueryArray(key string) []string {
@@ -568,10 +802,24 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error
 	return err
 }
 
+// SaveUploadedFileStream copies the form file into dst, e.g. the writer of a remote
+// storage upload, without going through a local file.
+func (c *Context) SaveUploadedFileStream(file *multipart.FileHeader, dst io.Writer) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
 // Bind checks the Content-Type to select a binding engine automatically,
 // Depending the "Content-Type" header different bindings are used:
 //     "application/json" --> JSON binding
 //     "application/xml"  --> XML binding
+//     "text/plain"       --> Plain binding
 // otherwise --> returns an error.
 // It parses the request's body as JSON if Content-Type == "application/json" using JSON or XML as a JSON input.
 // It decodes the json payload into the struct specified as a pointer.
@@ -605,6 +853,7 @@ func (c *Context) BindHeader(obj interface{}) error {
 // It will abort the request with HTTP 400 if any error occurs.
 func (c *Context) BindUri(obj interface{}) error {
 	if err := c.ShouldBindUri(obj); err != nil {
+		debugPrintBindError(obj, err)
 		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind) // nolint: errcheck
 		return err
 	}
@@ -612,11 +861,16 @@ func (c *Context) BindUri(obj interface{}) error {
 }
 
 // MustBindWith binds the passed struct pointer using the specified binding engine.
-// It will abort the request with HTTP 400 if any error occurs.
+// It will abort the request with HTTP 400 if any error occurs, or HTTP 413 for ErrBodyTooLarge.
 // See the binding package.
 func (c *Context) MustBindWith(obj interface{}, b binding.Binding) error {
 	if err := c.ShouldBindWith(obj, b); err != nil {
-		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind) // nolint: errcheck
+		debugPrintBindError(obj, err)
+		code := http.StatusBadRequest
+		if errors.Is(err, ErrBodyTooLarge) {
+			code = http.StatusRequestEntityTooLarge
+		}
+		c.AbortWithError(code, err).SetType(ErrorTypeBind) // nolint: errcheck
 		return err
 	}
 	return nil
@@ -635,6 +889,34 @@ func (c *Context) ShouldBind(obj interface{}) error {
 	return c.ShouldBindWith(obj, b)
 }
 
+// ShouldBindDefault is like c.ShouldBind() but it also returns the binding engine selected
+// from the Content-Type, so the caller can render its own error depending on the input format.
+// It never writes the response status code nor aborts.
+func (c *Context) ShouldBindDefault(obj interface{}) (binding.Binding, error) {
+	b := binding.Default(c.Request.Method, c.ContentType())
+	return b, c.ShouldBindWith(obj, b)
+}
+
+// BindAndValidate is like c.ShouldBind() but it returns the error as a map of messages
+// and false when the binding fails, it never writes the response nor aborts.
+// The validation errors reporting a field, such as the go-playground/validator ones,
+// are keyed by the field name, any other error is keyed by "error".
+func (c *Context) BindAndValidate(obj interface{}) (map[string]string, bool) {
+	err := c.ShouldBind(obj)
+	if err == nil {
+		return nil, true
+	}
+	errs := fieldErrors(err)
+	if len(errs) == 0 {
+		return map[string]string{"error": err.Error()}, false
+	}
+	messages := make(map[string]string, len(errs))
+	for _, fe := range errs {
+		messages[fe.Field()] = fe.Error()
+	}
+	return messages, false
+}
+
 // ShouldBindJSON is a shortcut for c.ShouldBindWith(obj, binding.JSON).
 func (c *Context) ShouldBindJSON(obj interface{}) error {
 	return c.ShouldBindWith(obj, binding.JSON)
@@ -645,6 +927,12 @@ func (c *Context) ShouldBindXML(obj interface{}) error {
 	return c.ShouldBindWith(obj, binding.XML)
 }
 
+// ShouldBindPlain is a shortcut for c.ShouldBindWith(obj, binding.Plain),
+// obj must be a *string or a *[]byte.
+func (c *Context) ShouldBindPlain(obj interface{}) error {
+	return c.ShouldBindWith(obj, binding.Plain)
+}
+
 // ShouldBindQuery is a shortcut for c.ShouldBindWith(obj, binding.Query).
 func (c *Context) ShouldBindQuery(obj interface{}) error {
 	return c.ShouldBindWith(obj, binding.Query)
@@ -664,9 +952,26 @@ func (c *Context) ShouldBindUri(obj interface{}) error {
 	return binding.Uri.BindUri(m, obj)
 }
 
+// ShouldBindRequest binds the request metadata to the fields of the passed struct pointer
+// tagged with `req:"method"`, `req:"path"` or `req:"remote_ip"`, e.g. for audit logging.
+// The remote ip is the one returned by c.ClientIP().
+func (c *Context) ShouldBindRequest(obj interface{}) error {
+	m := map[string][]string{
+		"method":    {c.Request.Method},
+		"path":      {c.Request.URL.Path},
+		"remote_ip": {c.ClientIP()},
+	}
+	return binding.Request.BindUri(m, obj)
+}
+
 // ShouldBindWith binds the passed struct pointer using the specified binding engine.
+// A binding of the body, such as binding.JSON, fails with binding.ErrEmptyBody without
+// reading anything when the request has no body, e.g. a GET request.
 // See the binding package.
 func (c *Context) ShouldBindWith(obj interface{}, b binding.Binding) error {
+	if _, ok := b.(binding.BindingBody); ok && (c.Request.Body == nil || c.Request.Body == http.NoBody) {
+		return binding.ErrEmptyBody
+	}
 	return b.Bind(c.Request, obj)
 }
 
@@ -693,17 +998,22 @@ func (c *Context) ShouldBindBodyWith(obj interface{}, bb binding.BindingBody) (e
 }
 
 // ClientIP implements a best effort algorithm to return the real client IP, it parses
-// X-Real-IP and X-Forwarded-For in order to work properly with reverse-proxies such us: nginx or haproxy.
+// the engine RemoteIPHeaders, X-Forwarded-For and X-Real-IP by default, in order to work
+// properly with reverse-proxies such us: nginx or haproxy.
 // Use X-Forwarded-For before X-Real-Ip as nginx uses X-Real-Ip with the proxy's IP.
+// When the engine has trusted proxies, the headers are only honored if the request comes
+// from one of them, and the lists of ips are read from the right, skipping the trusted proxies.
 func (c *Context) ClientIP() string {
-	if c.engine.ForwardedByClientIP {
-		clientIP := c.requestHeader("X-Forwarded-For")
-		clientIP = strings.TrimSpace(strings.Split(clientIP, ",")[0])
-		if clientIP == "" {
-			clientIP = strings.TrimSpace(c.requestHeader("X-Real-Ip"))
-		}
-		if clientIP != "" {
-			return clientIP
+	remoteIP, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		remoteIP = ""
+	}
+
+	if c.engine.ForwardedByClientIP && c.engine.isTrustedProxy(net.ParseIP(remoteIP)) {
+		for _, header := range c.engine.RemoteIPHeaders {
+			if clientIP := c.headerIP(header); clientIP != "" {
+				return clientIP
+			}
 		}
 	}
 
@@ -713,10 +1023,31 @@ func (c *Context) ClientIP() string {
 		}
 	}
 
-	if ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr)); err == nil {
+	return remoteIP
+}
+
+// headerIP returns the client ip of a header holding a comma separated list of ips, the first
+// one when every proxy is trusted, otherwise the last one which was not added by a trusted proxy.
+// It returns "" if the header is missing or the ip is not valid.
+func (c *Context) headerIP(header string) string {
+	ips := strings.Split(c.requestHeader(header), ",")
+	if c.engine.trustedCIDRs == nil {
+		ip := strings.TrimSpace(ips[0])
+		if net.ParseIP(ip) == nil {
+			return ""
+		}
 		return ip
 	}
-
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(ips[i])
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			return ""
+		}
+		if i == 0 || !c.engine.isTrustedProxy(parsed) {
+			return ip
+		}
+	}
 	return ""
 }
 
@@ -725,6 +1056,26 @@ func (c *Context) ContentType() string {
 	return filterFlags(c.requestHeader("Content-Type"))
 }
 
+// MultipartBoundary returns the boundary of a multipart request from its Content-Type,
+// without reading the body. It returns false when the request is not multipart.
+func (c *Context) MultipartBoundary() (string, bool) {
+	mediaType, params, err := mime.ParseMediaType(c.requestHeader("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return "", false
+	}
+	return params["boundary"], true
+}
+
+// ContentLength returns the Content-Length header of the request,
+// or -1 when it is missing or invalid, e.g. for a chunked body.
+func (c *Context) ContentLength() int64 {
+	n, err := strconv.ParseInt(c.requestHeader("Content-Length"), 10, 64)
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
+
 // IsWebsocket returns true if the request headers indicate that a websocket
 // handshake is being initiated by the client.
 func (c *Context) IsWebsocket() bool {
@@ -735,6 +1086,44 @@ func (c *Context) IsWebsocket() bool {
 	return false
 }
 
+// ClientCertificate returns the first certificate presented by the client over TLS,
+// e.g. for the mutual TLS endpoints. It returns false for plain HTTP or without certificate.
+func (c *Context) ClientCertificate() (*x509.Certificate, bool) {
+	if c.Request.TLS == nil || len(c.Request.TLS.PeerCertificates) == 0 {
+		return nil, false
+	}
+	return c.Request.TLS.PeerCertificates[0], true
+}
+
+// ProtoAtLeast reports whether the HTTP protocol used in the request is at least major.minor.
+func (c *Context) ProtoAtLeast(major, minor int) bool {
+	return c.Request.ProtoAtLeast(major, minor)
+}
+
+// KeepAlive reports whether the client wants to keep the connection open after the request:
+// by default from HTTP/1.1 unless the Connection header holds "close", and only with
+// "Connection: keep-alive" for HTTP/1.0.
+func (c *Context) KeepAlive() bool {
+	if c.Request.Close {
+		return false
+	}
+	var keepAlive, closing bool
+	for _, value := range c.Request.Header["Connection"] {
+		for _, token := range strings.Split(value, ",") {
+			token = strings.TrimSpace(token)
+			keepAlive = keepAlive || strings.EqualFold(token, "keep-alive")
+			closing = closing || strings.EqualFold(token, "close")
+		}
+	}
+	if closing {
+		return false
+	}
+	if c.ProtoAtLeast(1, 1) {
+		return true
+	}
+	return keepAlive
+}
+
 func (c *Context) requestHeader(key string) string {
 	return c.Request.Header.Get(key)
 }
@@ -772,11 +1161,59 @@ func (c *Context) Header(key, value string) {
 	c.Writer.Header().Set(key, value)
 }
 
+// SetHeaderIfAbsent sets a response header unless it is already present, so a middleware
+// can add the default headers, e.g. Cache-Control, without clobbering the handler ones.
+func (c *Context) SetHeaderIfAbsent(key, value string) {
+	header := c.Writer.Header()
+	if _, exists := header[textproto.CanonicalMIMEHeaderKey(key)]; !exists {
+		header.Set(key, value)
+	}
+}
+
+// CacheControl sets the Cache-Control header of the response from maxAge and the given
+// directives, e.g. c.CacheControl(time.Hour, gin.CachePublic, gin.CacheImmutable)
+// sets "public, max-age=3600, immutable".
+func (c *Context) CacheControl(maxAge time.Duration, opts ...CacheOption) {
+	directives := make([]string, 0, len(opts)+1)
+	var extensions []string
+	for _, opt := range opts {
+		switch opt {
+		case CachePublic, CachePrivate, CacheNoStore:
+			directives = append(directives, string(opt))
+		default:
+			extensions = append(extensions, string(opt))
+		}
+	}
+	directives = append(directives, "max-age="+strconv.FormatInt(int64(maxAge/time.Second), 10))
+	directives = append(directives, extensions...)
+	c.Header("Cache-Control", strings.Join(directives, ", "))
+}
+
+// NoCache sets the headers telling clients and proxies not to cache the response.
+func (c *Context) NoCache() {
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate")
+	c.Header("Pragma", "no-cache")
+	c.Header("Expires", "0")
+}
+
 // GetHeader returns value from request headers.
 func (c *Context) GetHeader(key string) string {
 	return c.requestHeader(key)
 }
 
+// GetHeaderValues returns all the values of the request header in the order
+// they were received, e.g. the repeated X-Forwarded-For headers.
+func (c *Context) GetHeaderValues(key string) []string {
+	return c.Request.Header.Values(key)
+}
+
+// NewRequest returns a request for a downstream call bound to the context of the
+// incoming request, so it is cancelled with it, e.g. when the client goes away
+// or the Engine.HandlerTimeout expires.
+func (c *Context) NewRequest(method, url string, body io.Reader) (*http.Request, error) {
+	return http.NewRequestWithContext(c.Request.Context(), method, url, body)
+}
+
 // GetRawData return stream data.
 func (c *Context) GetRawData() ([]byte, error) {
 	return ioutil.ReadAll(c.Request.Body)
@@ -806,6 +1243,22 @@ func (c *Context) SetCookie(name, value string, maxAge int, path, domain string,
 	})
 }
 
+// SetCookieData adds a Set-Cookie header for the given cookie, so its attributes,
+// e.g. SameSite, can be chosen for each cookie. Like SetCookie, the value is escaped
+// and an empty Path defaults to "/". An unset SameSite uses the one of c.SetSameSite.
+// The given cookie is left untouched, so it can be reused.
+func (c *Context) SetCookieData(cookie *http.Cookie) {
+	ck := *cookie
+	ck.Value = url.QueryEscape(ck.Value)
+	if ck.Path == "" {
+		ck.Path = "/"
+	}
+	if ck.SameSite == 0 {
+		ck.SameSite = c.sameSite
+	}
+	http.SetCookie(c.Writer, &ck)
+}
+
 // Cookie returns the named cookie provided in the request or
 // ErrNoCookie if not found. And return the named cookie is unescaped.
 // If multiple cookies match the given name, only one cookie will
@@ -829,11 +1282,33 @@ func (c *Context) Render(code int, r render.Render) {
 		return
 	}
 
-	if err := r.Render(c.Writer); err != nil {
+	if err := renderTo(c.Writer, r); err != nil {
+		// the client is gone, there is nobody left to answer
+		if isBrokenPipe(err) {
+			debugPrint("the client closed the connection while writing the response: %v", err)
+			c.Error(err) // nolint: errcheck
+			c.Abort()
+			return
+		}
 		panic(err)
 	}
 }
 
+// renderTo calls r.Render, some renderers panic on write errors instead of
+// returning them, those are turned back into errors.
+func renderTo(w ResponseWriter, r render.Render) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			e, ok := rec.(error)
+			if !ok || !isBrokenPipe(e) {
+				panic(rec)
+			}
+			err = e
+		}
+	}()
+	return r.Render(w)
+}
+
 // HTML renders the HTTP template specified by its file name.
 // It also updates the HTTP code and sets the Content-Type as "text/html".
 // See http://golang.org/doc/articles/wiki/
@@ -847,14 +1322,16 @@ func (c *Context) HTML(code int, name string, obj interface{}) {
 // WARNING: we recommend to use this only for development purposes since printing pretty JSON is
 // more CPU and bandwidth consuming. Use Context.JSON() instead.
 func (c *Context) IndentedJSON(code int, obj interface{}) {
-	c.Render(code, render.IndentedJSON{Data: obj})
+	c.omitJSONCharset()
+	c.Render(code, render.IndentedJSON{Data: c.jsonKeys(obj)})
 }
 
 // SecureJSON serializes the given struct as Secure JSON into the response body.
 // Default prepends "while(1)," to response body if the given struct is array values.
 // It also sets the Content-Type as "application/json".
 func (c *Context) SecureJSON(code int, obj interface{}) {
-	c.Render(code, render.SecureJSON{Prefix: c.engine.secureJsonPrefix, Data: obj})
+	c.omitJSONCharset()
+	c.Render(code, render.SecureJSON{Prefix: c.engine.secureJsonPrefix, Data: c.jsonKeys(obj)})
 }
 
 // JSONP serializes the given struct as JSON into the response body.
@@ -863,28 +1340,53 @@ func (c *Context) SecureJSON(code int, obj interface{}) {
 func (c *Context) JSONP(code int, obj interface{}) {
 	callback := c.DefaultQuery("callback", "")
 	if callback == "" {
-		c.Render(code, render.JSON{Data: obj})
+		c.Render(code, render.JSON{Data: c.jsonKeys(obj)})
 		return
 	}
-	c.Render(code, render.JsonpJSON{Callback: callback, Data: obj})
+	c.Render(code, render.JsonpJSON{Callback: callback, Data: c.jsonKeys(obj)})
 }
 
 // JSON serializes the given struct as JSON into the response body.
 // It also sets the Content-Type as "application/json".
 func (c *Context) JSON(code int, obj interface{}) {
-	c.Render(code, render.JSON{Data: obj})
+	c.omitJSONCharset()
+	c.Render(code, render.JSON{Data: c.jsonKeys(obj)})
+}
+
+// JSONOmitEmpty serializes the given struct as JSON into the response body, leaving
+// out the fields and map entries whose value is empty, as if they had the omitempty option.
+// It also sets the Content-Type as "application/json".
+func (c *Context) JSONOmitEmpty(code int, obj interface{}) {
+	c.omitJSONCharset()
+	c.Render(code, render.OmitEmptyJSON{Data: c.jsonKeys(obj)})
+}
+
+// omitJSONCharset sets a bare "application/json" Content-Type when the engine
+// JSONOmitCharset is enabled, unless a Content-Type was already set.
+func (c *Context) omitJSONCharset() {
+	if c.engine.JSONOmitCharset && c.Writer.Header().Get("Content-Type") == "" {
+		c.Header("Content-Type", MIMEJSON)
+	}
+}
+
+// JSONWithContentType serializes the given struct as JSON into the response body,
+// like JSON, but with the given Content-Type, e.g. "application/vnd.api+json".
+func (c *Context) JSONWithContentType(code int, contentType string, obj interface{}) {
+	c.Header("Content-Type", contentType)
+	c.Render(code, render.JSON{Data: c.jsonKeys(obj)})
 }
 
 // AsciiJSON serializes the given struct as JSON into the response body with unicode to ASCII string.
 // It also sets the Content-Type as "application/json".
 func (c *Context) AsciiJSON(code int, obj interface{}) {
-	c.Render(code, render.AsciiJSON{Data: obj})
+	c.Render(code, render.AsciiJSON{Data: c.jsonKeys(obj)})
 }
 
 // PureJSON serializes the given struct as JSON into the response body.
 // PureJSON, unlike JSON, does not replace special html characters with their unicode entities.
 func (c *Context) PureJSON(code int, obj interface{}) {
-	c.Render(code, render.PureJSON{Data: obj})
+	c.omitJSONCharset()
+	c.Render(code, render.PureJSON{Data: c.jsonKeys(obj)})
 }
 
 // XML serializes the given struct as XML into the response body.
@@ -893,11 +1395,45 @@ func (c *Context) XML(code int, obj interface{}) {
 	c.Render(code, render.XML{Data: obj})
 }
 
+// ProblemDetails describes an API error as defined by RFC 7807.
+type ProblemDetails struct {
+	XMLName  xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type     string   `json:"type,omitempty" xml:"type,omitempty"`
+	Title    string   `json:"title,omitempty" xml:"title,omitempty"`
+	Status   int      `json:"status,omitempty" xml:"status,omitempty"`
+	Detail   string   `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty" xml:"instance,omitempty"`
+}
+
+// Problem serializes the given problem details into the response body as "application/problem+json",
+// or as "application/problem+xml" when the client accepts XML rather than JSON.
+// The problem Status defaults to code.
+func (c *Context) Problem(code int, problem ProblemDetails) {
+	if problem.Status == 0 {
+		problem.Status = code
+	}
+	switch c.NegotiateFormat(binding.MIMEJSON, binding.MIMEXML, binding.MIMEXML2, MIMEProblemJSON, MIMEProblemXML) {
+	case binding.MIMEXML, binding.MIMEXML2, MIMEProblemXML:
+		c.Render(code, render.ProblemXML{Data: problem})
+	default:
+		c.Render(code, render.ProblemJSON{Data: problem})
+	}
+}
+
 // String writes the given string into the response body.
 func (c *Context) String(code int, format string, values ...interface{}) {
 	c.Render(code, render.String{Format: format, Data: values})
 }
 
+// SSEvent writes a Server-Sent Event into the body stream and flushes it.
+// A string message is sent as is, other values are encoded as JSON.
+func (c *Context) SSEvent(name string, message interface{}) {
+	c.Render(-1, render.SSEvent{
+		Event: name,
+		Data:  message,
+	})
+}
+
 // Redirect returns a HTTP redirect to the specific location.
 func (c *Context) Redirect(code int, location string) {
 	c.Render(-1, render.Redirect{
@@ -908,7 +1444,11 @@ func (c *Context) Redirect(code int, location string) {
 }
 
 // Data writes some data into the body stream and updates the HTTP code.
+// An empty contentType is replaced by the engine DefaultDataContentType.
 func (c *Context) Data(code int, contentType string, data []byte) {
+	if contentType == "" {
+		contentType = c.engine.DefaultDataContentType
+	}
 	c.Render(code, render.Data{
 		ContentType: contentType,
 		Data:        data,
@@ -927,9 +1467,105 @@ func (c *Context) DataFromReader(code int, contentLength int64, contentType stri
 
 // File writes the specified file into the body stream in a efficient way.
 func (c *Context) File(filepath string) {
+	if info, err := os.Stat(filepath); err == nil {
+		setFileETag(c.Writer, info)
+	}
 	http.ServeFile(c.Writer, c.Request, filepath)
 }
 
+// setFileETag sets a weak ETag built from the size and the modification time of a
+// file, http.ServeContent then answers the matching If-None-Match with a 304 code.
+func setFileETag(w http.ResponseWriter, info os.FileInfo) {
+	if info.IsDir() || w.Header().Get("ETag") != "" {
+		return
+	}
+	w.Header().Set("ETag", fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano()))
+}
+
+// FileGzip writes the specified file gzipped on the fly when the client accepts it,
+// which suits large text files, e.g. a log or a CSV export. Otherwise, or if the file
+// can not be opened, it falls back to c.File. Range requests are not supported gzipped.
+func (c *Context) FileGzip(filepath string) {
+	if !acceptsGzip(c.requestHeader("Accept-Encoding")) {
+		c.File(filepath)
+		return
+	}
+	f, err := os.Open(filepath)
+	if err != nil {
+		c.File(filepath)
+		return
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		c.File(filepath)
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(filepath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Writer.Header().Add("Vary", "Accept-Encoding")
+	c.writeGzip(contentType, f)
+}
+
+// writeGzip writes the content of r gzipped with a 200 code.
+func (c *Context) writeGzip(contentType string, r io.Reader) {
+	header := c.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+	c.Status(http.StatusOK)
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+
+	gz := gzip.NewWriter(c.Writer)
+	if _, err := io.Copy(gz, r); err != nil {
+		c.Error(err) // nolint: errcheck
+	}
+	if err := gz.Close(); err != nil {
+		c.Error(err) // nolint: errcheck
+	}
+}
+
+// compressible reports whether the content type is worth gzipping, the images
+// but SVG, the videos and the archives are already compressed.
+func compressible(contentType string) bool {
+	contentType = filterFlags(contentType)
+	switch {
+	case strings.HasPrefix(contentType, "text/"),
+		strings.HasSuffix(contentType, "+json"),
+		strings.HasSuffix(contentType, "+xml"):
+		return true
+	}
+	switch contentType {
+	case "application/json", "application/javascript", "application/x-javascript",
+		"application/xml", "application/wasm":
+		return true
+	}
+	return false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response.
+func acceptsGzip(acceptEncoding string) bool {
+	star := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params := part, ""
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			coding, params = part[:i], part[i+1:]
+		}
+		accepted := qValue(params) > 0
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip":
+			return accepted
+		case "*":
+			star = accepted
+		}
+	}
+	return star
+}
+
 // FileFromFS writes the specified file from http.FileSytem into the body stream in an efficient way.
 func (c *Context) FileFromFS(filepath string, fs http.FileSystem) {
 	defer func(old string) {
@@ -938,16 +1574,66 @@ func (c *Context) FileFromFS(filepath string, fs http.FileSystem) {
 
 	c.Request.URL.Path = filepath
 
+	if f, err := fs.Open(filepath); err == nil {
+		if info, err := f.Stat(); err == nil {
+			setFileETag(c.Writer, info)
+		}
+		f.Close()
+	}
 	http.FileServer(fs).ServeHTTP(c.Writer, c.Request)
 }
 
 // FileAttachment writes the specified file into the body stream in an efficient way
 // On the client side, the file will typically be downloaded with the given filename
 func (c *Context) FileAttachment(filepath, filename string) {
-	c.Writer.Header().Set("content-disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Writer.Header().Set("content-disposition", contentDisposition("attachment", filename))
+	if info, err := os.Stat(filepath); err == nil {
+		setFileETag(c.Writer, info)
+	}
 	http.ServeFile(c.Writer, c.Request, filepath)
 }
 
+// contentDisposition builds a Content-Disposition header value for filename.
+// Non-ASCII names also get the RFC 5987 filename* parameter, the quoted
+// filename then holds an ASCII fallback for the older clients.
+func contentDisposition(disposition, filename string) string {
+	fallback := make([]byte, 0, len(filename))
+	ascii := true
+	for _, r := range filename {
+		if r >= utf8.RuneSelf {
+			ascii = false
+			r = '_'
+		}
+		fallback = append(fallback, byte(r))
+	}
+	if ascii {
+		return fmt.Sprintf("%s; filename=\"%s\"", disposition, filename)
+	}
+	return fmt.Sprintf("%s; filename=\"%s\"; filename*=UTF-8''%s",
+		disposition, fallback, strings.Replace(url.QueryEscape(filename), "+", "%20", -1))
+}
+
+// Push initiates an HTTP/2 server push of the given paths, the pushed requests carry
+// the Accept-Encoding of the current request. It does nothing when push is not
+// supported, e.g. over HTTP/1, and returns the first push error otherwise.
+func (c *Context) Push(paths ...string) error {
+	pusher := c.Writer.Pusher()
+	if pusher == nil {
+		return nil
+	}
+
+	var opts *http.PushOptions
+	if encoding := c.requestHeader("Accept-Encoding"); encoding != "" {
+		opts = &http.PushOptions{Header: http.Header{"Accept-Encoding": []string{encoding}}}
+	}
+	for _, path := range paths {
+		if err := pusher.Push(path, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Stream sends a streaming response and returns a boolean
 // indicates "Is client disconnected in middle of stream"
 func (c *Context) Stream(step func(w io.Writer) bool) bool {
@@ -967,6 +1653,60 @@ func (c *Context) Stream(step func(w io.Writer) bool) bool {
 	}
 }
 
+// StreamReader pipes the reader into the response body with the given status code and
+// content type, flushing after every chunk. It returns true if the client disconnected
+// before the reader was drained. A read error ends the stream and is attached to the context.
+func (c *Context) StreamReader(code int, contentType string, reader io.Reader) bool {
+	c.Status(code)
+	c.Header("Content-Type", contentType)
+	buf := make([]byte, 32*1024)
+	return c.Stream(func(w io.Writer) bool {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return false
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				c.Error(err) // nolint: errcheck
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// JSONStream writes the values received from ch as the elements of a JSON array,
+// encoding and flushing each one as it arrives instead of marshaling the whole slice in memory.
+// The array is closed when ch is closed. It returns true if the client disconnected in the middle of the stream.
+// An encoding error ends the array early and is attached to the context.
+// A Content-Type already set is kept, the default one follows Engine.JSONOmitCharset.
+func (c *Context) JSONStream(code int, ch <-chan interface{}) bool {
+	c.Status(code)
+	c.omitJSONCharset()
+	if c.Writer.Header().Get("Content-Type") == "" {
+		c.Header("Content-Type", "application/json; charset=utf-8")
+	}
+	sep := []byte("[")
+	return c.Stream(func(w io.Writer) bool {
+		if v, ok := <-ch; ok {
+			data, err := render.JSONMarshal(c.jsonKeys(v))
+			if err == nil {
+				w.Write(append(sep, data...)) // nolint: errcheck
+				sep = []byte(",")
+				return true
+			}
+			c.Error(err) // nolint: errcheck
+		}
+		if sep[0] == '[' {
+			w.Write(sep) // nolint: errcheck
+		}
+		w.Write([]byte("]")) // nolint: errcheck
+		return false
+	})
+}
+
 /************************************/
 /******** CONTENT NEGOTIATION *******/
 /************************************/
@@ -1003,6 +1743,10 @@ func (c *Context) Negotiate(code int, config Negotiate) {
 }
 
 // NegotiateFormat returns an acceptable Accept format.
+// The accepted formats are tried by decreasing q-value, e.g. with
+// "text/html;q=0.8, application/xml;q=0.9" XML wins over HTML whatever the offered order.
+// The MIME aliases, see binding.RegisterMIMEAlias, are resolved on both sides and the
+// matching offer is returned as given.
 func (c *Context) NegotiateFormat(offered ...string) string {
 	assert1(len(offered) > 0, "you must provide at least one offer")
 
@@ -1013,20 +1757,22 @@ func (c *Context) NegotiateFormat(offered ...string) string {
 		return offered[0]
 	}
 	for _, accepted := range c.Accepted {
-		for _, offer := range offered {
+		accepted = binding.CanonicalMIME(accepted)
+		for _, original := range offered {
+			offer := binding.CanonicalMIME(original)
 			// According to RFC 2616 and RFC 2396, non-ASCII characters are not allowed in headers,
 			// therefore we can just iterate over the string without casting it into []rune
 			i := 0
-			for ; i < len(accepted); i++ {
+			for ; i < len(accepted) && i < len(offer); i++ {
 				if accepted[i] == '*' || offer[i] == '*' {
-					return offer
+					return original
 				}
 				if accepted[i] != offer[i] {
 					break
 				}
 			}
-			if i == len(accepted) {
-				return offer
+			if i == len(accepted) && i == len(offer) {
+				return original
 			}
 		}
 	}
@@ -1044,22 +1790,39 @@ func (c *Context) SetAccepted(formats ...string) {
 
 // Deadline always returns that there is no deadline (ok==false),
 // maybe you want to use Request.Context().Deadline() instead.
+// With Engine.HandlerTimeout set, it returns the deadline of the request instead.
 func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	if c.requestDeadline() {
+		return c.Request.Context().Deadline()
+	}
 	return
 }
 
 // Done always returns nil (chan which will wait forever),
 // if you want to abort your work when the connection was closed
 // you should use Request.Context().Done() instead.
+// With Engine.HandlerTimeout set, it returns Request.Context().Done().
 func (c *Context) Done() <-chan struct{} {
+	if c.requestDeadline() {
+		return c.Request.Context().Done()
+	}
 	return nil
 }
 
 // Err always returns nil, maybe you want to use Request.Context().Err() instead.
+// With Engine.HandlerTimeout set, it returns Request.Context().Err().
 func (c *Context) Err() error {
+	if c.requestDeadline() {
+		return c.Request.Context().Err()
+	}
 	return nil
 }
 
+// requestDeadline reports whether the context follows the deadline of the request.
+func (c *Context) requestDeadline() bool {
+	return c.deadline && c.Request != nil
+}
+
 // Value returns the value associated with this context for key, or nil
 // if no value is associated with key. Successive calls to Value with
 // the same key returns the same result.
@@ -6,23 +6,33 @@ package gin
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"fmt"
 	"html/template"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/go-playground/assert"
 	"github.com/manucorporat/gin-diet/binding"
+	"github.com/manucorporat/gin-diet/internal/json"
 )
 
 var _ context.Context = &Context{}
@@ -148,6 +158,28 @@ func TestSaveUploadedCreateFailed(t *testing.T) {
 	assert.NotEqual(t, c.SaveUploadedFile(f, "/"), nil)
 }
 
+func TestSaveUploadedFileStream(t *testing.T) {
+	content := bytes.Repeat([]byte("gin"), 1024)
+	buf := new(bytes.Buffer)
+	mw := multipart.NewWriter(buf)
+	w, err := mw.CreateFormFile("file", "test")
+	assert.Equal(t, err, nil)
+	_, err = w.Write(content)
+	assert.Equal(t, err, nil)
+	mw.Close()
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", buf)
+	c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+	f, err := c.FormFile("file")
+	assert.Equal(t, err, nil)
+
+	var dst bytes.Buffer
+	assert.Equal(t, nil, c.SaveUploadedFileStream(f, &dst))
+	assert.Equal(t, content, dst.Bytes())
+
+	assert.NotEqual(t, nil, c.SaveUploadedFileStream(&multipart.FileHeader{Filename: "file"}, &dst))
+}
+
 func TestContextReset(t *testing.T) {
 	router := New()
 	c := router.allocateContext()
@@ -253,6 +285,22 @@ func TestContextGetInt64(t *testing.T) {
 	assert.Equal(t, int64(42424242424242), c.GetInt64("int64"))
 }
 
+func TestContextGetUint(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Set("uint", uint(1))
+	assert.Equal(t, uint(1), c.GetUint("uint"))
+	assert.Equal(t, uint(0), c.GetUint("missing"))
+}
+
+func TestContextGetUint64(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Set("uint64", uint64(18446744073709551615))
+	assert.Equal(t, uint64(18446744073709551615), c.GetUint64("uint64"))
+	// a uint is not a uint64
+	c.Set("uint", uint(1))
+	assert.Equal(t, uint64(0), c.GetUint64("uint"))
+}
+
 func TestContextGetFloat64(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.Set("float64", 4.2)
@@ -308,6 +356,30 @@ func TestContextGetStringMapStringSlice(t *testing.T) {
 	assert.Equal(t, []string{"foo"}, c.GetStringMapStringSlice("map")["foo"])
 }
 
+func TestContextGetStringMapInt(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	var m = make(map[string]int)
+	m["foo"] = 42
+	c.Set("map", m)
+
+	assert.Equal(t, m, c.GetStringMapInt("map"))
+	assert.Equal(t, 42, c.GetStringMapInt("map")["foo"])
+	assert.Equal(t, map[string]int(nil), c.GetStringMapInt("missing"))
+}
+
+func TestContextGetStringMapInt64(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	var m = make(map[string]int64)
+	m["foo"] = 42424242424242
+	c.Set("map", m)
+
+	assert.Equal(t, m, c.GetStringMapInt64("map"))
+	assert.Equal(t, int64(42424242424242), c.GetStringMapInt64("map")["foo"])
+	// a map[string]int is not a map[string]int64
+	c.Set("ints", map[string]int{"foo": 1})
+	assert.Equal(t, map[string]int64(nil), c.GetStringMapInt64("ints"))
+}
+
 func TestContextCopy(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.index = 2
@@ -367,6 +439,51 @@ func TestContextHandler(t *testing.T) {
 	assert.Equal(t, reflect.ValueOf(handlerTest).Pointer(), reflect.ValueOf(c.Handler()).Pointer())
 }
 
+func TestContextEngine(t *testing.T) {
+	c, engine := CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, true, c.Engine() == engine)
+	assert.Equal(t, true, c.Copy().Engine() == engine)
+
+	var got *Engine
+	router := New()
+	router.GET("/", func(c *Context) {
+		got = c.Engine()
+	})
+	performRequest(router, "GET", "/")
+	assert.Equal(t, true, got == router)
+}
+
+func TestContextQueryTyped(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "http://example.com/?page=2&size=big&debug=true&verbose=maybe&empty=", nil)
+
+	i, ok := c.QueryInt("page")
+	assert.Equal(t, 2, i)
+	assert.Equal(t, true, ok)
+	i, ok = c.QueryInt("size")
+	assert.Equal(t, 0, i)
+	assert.Equal(t, false, ok)
+	i, ok = c.QueryInt("id")
+	assert.Equal(t, 0, i)
+	assert.Equal(t, false, ok)
+	_, ok = c.QueryInt("empty")
+	assert.Equal(t, false, ok)
+
+	assert.Equal(t, 2, c.DefaultQueryInt("page", 1))
+	assert.Equal(t, 10, c.DefaultQueryInt("size", 10))
+	assert.Equal(t, 1, c.DefaultQueryInt("id", 1))
+
+	b, ok := c.QueryBool("debug")
+	assert.Equal(t, true, b)
+	assert.Equal(t, true, ok)
+	b, ok = c.QueryBool("verbose")
+	assert.Equal(t, false, b)
+	assert.Equal(t, false, ok)
+	b, ok = c.QueryBool("id")
+	assert.Equal(t, false, b)
+	assert.Equal(t, false, ok)
+}
+
 func TestContextQuery(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.Request, _ = http.NewRequest("GET", "http://example.com/?foo=bar&page=10&id=", nil)
@@ -606,6 +723,30 @@ func TestContextSetCookiePathEmpty(t *testing.T) {
 	assert.Equal(t, "user=gin; Path=/; Domain=localhost; Max-Age=1; HttpOnly; Secure; SameSite=Lax", c.Writer.Header().Get("Set-Cookie"))
 }
 
+func TestContextSetCookieData(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookieData(&http.Cookie{Name: "session", Value: "a b", SameSite: http.SameSiteLaxMode, HttpOnly: true})
+	c.SetCookieData(&http.Cookie{Name: "widget", Value: "gin", Path: "/embed", SameSite: http.SameSiteNoneMode, Secure: true})
+	c.SetCookieData(&http.Cookie{Name: "user", Value: "gin"})
+
+	assert.Equal(t, []string{
+		"session=a+b; Path=/; HttpOnly; SameSite=Lax",
+		"widget=gin; Path=/embed; Secure; SameSite=None",
+		"user=gin; Path=/; SameSite=Strict",
+	}, c.Writer.Header()["Set-Cookie"])
+}
+
+func TestContextSetCookieDataTwice(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	cookie := &http.Cookie{Name: "session", Value: "a b"}
+	c.SetCookieData(cookie)
+	c.SetCookieData(cookie)
+
+	assert.Equal(t, []string{"session=a+b; Path=/", "session=a+b; Path=/"}, c.Writer.Header()["Set-Cookie"])
+	assert.Equal(t, &http.Cookie{Name: "session", Value: "a b"}, cookie)
+}
+
 func TestContextGetCookie(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.Request, _ = http.NewRequest("GET", "/get", nil)
@@ -646,6 +787,36 @@ func TestContextRenderPanicIfErr(t *testing.T) {
 	t.Error("Panic not detected")
 }
 
+// brokenPipeWriter fails every write as if the client had closed the connection.
+type brokenPipeWriter struct {
+	*httptest.ResponseRecorder
+	errno syscall.Errno
+}
+
+func (w *brokenPipeWriter) Write([]byte) (int, error) {
+	return 0, &net.OpError{Op: "write", Net: "tcp", Err: &os.SyscallError{Syscall: "write", Err: w.errno}}
+}
+
+func TestContextRenderBrokenPipe(t *testing.T) {
+	for _, errno := range []syscall.Errno{syscall.EPIPE, syscall.ECONNRESET} {
+		var errs []*Error
+		router := New()
+		router.GET("/", func(c *Context) {
+			c.JSON(http.StatusOK, H{"foo": "bar"})
+			assert.Equal(t, true, c.IsAborted())
+			errs = c.Errors
+		})
+
+		w := &brokenPipeWriter{httptest.NewRecorder(), errno}
+		req, _ := http.NewRequest("GET", "/", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, len(errs))
+		assert.Equal(t, true, errors.Is(errs[0].Err, errno))
+	}
+}
+
 // Tests that the response is serialized as JSON
 // and Content-Type is set to application/json
 // and special HTML characters are escaped
@@ -660,6 +831,44 @@ func TestContextRenderJSON(t *testing.T) {
 	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestContextRenderJSONOmitEmpty(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.JSONOmitEmpty(http.StatusOK, struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Admin bool   `json:"admin"`
+	}{ID: 1})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"id":1}`, w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestContextRenderJSONOmitCharset(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, engine := CreateTestContext(w)
+	engine.JSONOmitCharset = true
+
+	c.JSON(http.StatusOK, H{"foo": "bar"})
+	assert.Equal(t, "{\"foo\":\"bar\"}", w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	c, engine = CreateTestContext(w)
+	engine.JSONOmitCharset = true
+	c.IndentedJSON(http.StatusOK, H{"foo": "bar"})
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	c, engine = CreateTestContext(w)
+	engine.JSONOmitCharset = true
+	c.Header("Content-Type", "application/vnd.api+json")
+	c.JSON(http.StatusOK, H{"foo": "bar"})
+	assert.Equal(t, "application/vnd.api+json", w.Header().Get("Content-Type"))
+}
+
 // Tests that the response is serialized as JSONP
 // and Content-Type is set to application/javascript
 func TestContextRenderJSONP(t *testing.T) {
@@ -714,6 +923,17 @@ func TestContextRenderAPIJSON(t *testing.T) {
 	assert.Equal(t, "application/vnd.api+json", w.Header().Get("Content-Type"))
 }
 
+func TestContextRenderJSONWithContentType(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.JSONWithContentType(http.StatusCreated, "application/vnd.api+json", H{"foo": "bar", "html": "<b>"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "{\"foo\":\"bar\",\"html\":\"\\u003cb\\u003e\"}", w.Body.String())
+	assert.Equal(t, "application/vnd.api+json", w.Header().Get("Content-Type"))
+}
+
 // Tests that no Custom JSON is rendered if code is 204
 func TestContextRenderNoContentAPIJSON(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -868,6 +1088,49 @@ func TestContextRenderXML(t *testing.T) {
 	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestContextRenderProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/users/1", nil)
+	c.Request.Header.Add("Accept", "application/json")
+
+	c.Problem(http.StatusNotFound, ProblemDetails{
+		Type:     "https://example.com/probs/not-found",
+		Title:    "User not found",
+		Detail:   "user 1 does not exist",
+		Instance: "/users/1",
+	})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"type":"https://example.com/probs/not-found","title":"User not found","status":404,"detail":"user 1 does not exist","instance":"/users/1"}`, w.Body.String())
+}
+
+func TestContextRenderProblemXML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Add("Accept", "application/xml, application/json;q=0.9")
+
+	c.Problem(http.StatusBadRequest, ProblemDetails{Title: "Invalid", Status: http.StatusUnprocessableEntity})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/problem+xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, `<problem xmlns="urn:ietf:rfc:7807"><title>Invalid</title><status>422</status></problem>`, w.Body.String())
+}
+
+func TestContextRenderProblemDefault(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Add("Accept", "text/html")
+
+	c.Problem(http.StatusInternalServerError, ProblemDetails{Title: "Oops"})
+
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"title":"Oops","status":500}`, w.Body.String())
+}
+
 // Tests that no XML is rendered if code is 204
 func TestContextRenderNoContentXML(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -945,6 +1208,17 @@ func TestContextRenderData(t *testing.T) {
 	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
 }
 
+func TestContextRenderDataDefaultContentType(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, router := CreateTestContext(w)
+	router.DefaultDataContentType = "application/octet-stream"
+
+	c.Data(http.StatusOK, "", []byte(`foo,bar`))
+
+	assert.Equal(t, "foo,bar", w.Body.String())
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+}
+
 // Tests that no Custom Data is rendered if code is 204
 func TestContextRenderNoContentData(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -969,6 +1243,65 @@ func TestContextRenderFile(t *testing.T) {
 	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestContextRenderFileGzip(t *testing.T) {
+	content := strings.Repeat("id,name\n1,manu\n", 1000)
+	f, err := ioutil.TempFile("", "export*.csv")
+	assert.Equal(t, nil, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(content)
+	assert.Equal(t, nil, err)
+	f.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Accept-Encoding", "deflate, gzip;q=0.8")
+	c.FileGzip(f.Name())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, true, w.Body.Len() < len(content))
+	gz, err := gzip.NewReader(w.Body)
+	assert.Equal(t, nil, err)
+	body, err := ioutil.ReadAll(gz)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, content, string(body))
+
+	// gzip not accepted, served as is
+	w = httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Accept-Encoding", "gzip;q=0, *")
+	c.FileGzip(f.Name())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, content, w.Body.String())
+
+	// missing file
+	w = httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Accept-Encoding", "gzip")
+	c.FileGzip(f.Name() + ".missing")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	assert.Equal(t, true, acceptsGzip("gzip"))
+	assert.Equal(t, true, acceptsGzip("deflate, GZIP;q=0.5"))
+	assert.Equal(t, true, acceptsGzip("*"))
+	assert.Equal(t, false, acceptsGzip(""))
+	assert.Equal(t, false, acceptsGzip("deflate, br"))
+	assert.Equal(t, false, acceptsGzip("gzip; q=0"))
+	assert.Equal(t, false, acceptsGzip("*, gzip;q=0"))
+	assert.Equal(t, false, acceptsGzip("*;q=0"))
+}
+
 func TestContextRenderFileFromFS(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := CreateTestContext(w)
@@ -995,6 +1328,64 @@ func TestContextRenderAttachment(t *testing.T) {
 	assert.Equal(t, fmt.Sprintf("attachment; filename=\"%s\"", newFilename), w.HeaderMap.Get("Content-Disposition"))
 }
 
+func TestContextRenderSSE(t *testing.T) {
+	w := CreateTestResponseRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.SSEvent("ping", "pong")
+	c.SSEvent("user", H{"name": "gin"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "event: ping\ndata: pong\n\nevent: user\ndata: {\"name\":\"gin\"}\n\n", w.Body.String())
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+}
+
+func TestContextRenderAttachmentUnicode(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.FileAttachment("./gin.go", "résumé 2020.pdf")
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `attachment; filename="r_sum_ 2020.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9%202020.pdf`, w.Header().Get("Content-Disposition"))
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+	opts   []*http.PushOptions
+	err    error
+}
+
+func (w *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	w.pushed = append(w.pushed, target)
+	w.opts = append(w.opts, opts)
+	return w.err
+}
+
+func TestContextPush(t *testing.T) {
+	// no pusher, e.g. HTTP/1
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	assert.Equal(t, nil, c.Push("/assets/app.js"))
+
+	w := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	c, _ = CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Set("Accept-Encoding", "gzip")
+	assert.Equal(t, nil, c.Push("/assets/app.js", "/assets/style.css"))
+	assert.Equal(t, []string{"/assets/app.js", "/assets/style.css"}, w.pushed)
+	assert.Equal(t, "gzip", w.opts[1].Header.Get("Accept-Encoding"))
+
+	w = &pushRecorder{ResponseRecorder: httptest.NewRecorder(), err: http.ErrNotSupported}
+	c, _ = CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	assert.Equal(t, http.ErrNotSupported, c.Push("/assets/app.js", "/assets/style.css"))
+	assert.Equal(t, []string{"/assets/app.js"}, w.pushed)
+	assert.Equal(t, (*http.PushOptions)(nil), w.opts[0])
+}
+
 func TestContextHeaders(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.Header("Content-Type", "text/plain")
@@ -1011,6 +1402,40 @@ func TestContextHeaders(t *testing.T) {
 	assert.Equal(t, false, exist)
 }
 
+func TestContextSetHeaderIfAbsent(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Header("Cache-Control", "no-store")
+
+	c.SetHeaderIfAbsent("cache-control", "public, max-age=60")
+	c.SetHeaderIfAbsent("X-Frame-Options", "DENY")
+
+	assert.Equal(t, []string{"no-store"}, c.Writer.Header().Values("Cache-Control"))
+	assert.Equal(t, "DENY", c.Writer.Header().Get("X-Frame-Options"))
+}
+
+func TestContextCacheControl(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+
+	c.CacheControl(time.Hour, CachePublic, CacheImmutable)
+	assert.Equal(t, "public, max-age=3600, immutable", c.Writer.Header().Get("Cache-Control"))
+
+	c.CacheControl(90*time.Second, CachePrivate)
+	assert.Equal(t, "private, max-age=90", c.Writer.Header().Get("Cache-Control"))
+
+	c.CacheControl(0, CacheNoStore)
+	assert.Equal(t, "no-store, max-age=0", c.Writer.Header().Get("Cache-Control"))
+}
+
+func TestContextNoCache(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.CacheControl(time.Hour, CachePublic)
+	c.NoCache()
+
+	assert.Equal(t, "no-store, no-cache, must-revalidate", c.Writer.Header().Get("Cache-Control"))
+	assert.Equal(t, "no-cache", c.Writer.Header().Get("Pragma"))
+	assert.Equal(t, "0", c.Writer.Header().Get("Expires"))
+}
+
 // TODO
 func TestContextRenderRedirectWithRelativePath(t *testing.T) {
 	w := httptest.NewRecorder()
@@ -1141,6 +1566,42 @@ func TestContextNegotiationFormatWithAccept(t *testing.T) {
 	assert.Equal(t, MIMEXML, c.NegotiateFormat(MIMEJSON, MIMEXML))
 	assert.Equal(t, MIMEHTML, c.NegotiateFormat(MIMEXML, MIMEHTML))
 	assert.Equal(t, 0, len(c.NegotiateFormat(MIMEJSON)))
+
+	// an accepted type longer or shorter than the offer is not a match
+	c.Accepted = nil
+	c.Request.Header.Set("Accept", "text/plainx, application/jso")
+	assert.Equal(t, "", c.NegotiateFormat(MIMEPlain, MIMEJSON))
+}
+
+func TestContextNegotiationFormatQuality(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", nil)
+	c.Request.Header.Add("Accept", "text/html;q=0.8, application/xml;q=0.9, application/json;q=0.5, */*;q=0.1")
+
+	assert.Equal(t, MIMEXML, c.NegotiateFormat(MIMEHTML, MIMEXML))
+	assert.Equal(t, MIMEXML, c.NegotiateFormat(MIMEJSON, MIMEHTML, MIMEXML))
+	assert.Equal(t, MIMEHTML, c.NegotiateFormat(MIMEJSON, MIMEHTML))
+	assert.Equal(t, MIMEJSON, c.NegotiateFormat(MIMEPlain, MIMEJSON))
+	assert.Equal(t, MIMEPlain, c.NegotiateFormat(MIMEPlain))
+
+	c.Accepted = nil
+	c.Request.Header.Set("Accept", "application/json;q=0, text/html")
+	assert.Equal(t, MIMEHTML, c.NegotiateFormat(MIMEJSON, MIMEHTML))
+	assert.Equal(t, "", c.NegotiateFormat(MIMEJSON))
+}
+
+func TestContextNegotiationFormatAlias(t *testing.T) {
+	binding.RegisterMIMEAlias("application/x-json", MIMEJSON)
+	defer binding.UnregisterMIMEAlias("application/x-json")
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", nil)
+	c.Request.Header.Add("Accept", MIMEJSON)
+	assert.Equal(t, "application/x-json", c.NegotiateFormat(MIMEXML, "application/x-json"))
+
+	c.Accepted = nil
+	c.Request.Header.Set("Accept", "application/x-json")
+	assert.Equal(t, MIMEJSON, c.NegotiateFormat(MIMEXML, MIMEJSON))
 }
 
 func TestContextNegotiationFormatWithWildcardAccept(t *testing.T) {
@@ -1240,6 +1701,125 @@ func TestContextAbortWithStatusJSON(t *testing.T) {
 	assert.Equal(t, fmt.Sprint("{\"foo\":\"fooValue\",\"bar\":\"barValue\"}"), jsonStringBody)
 }
 
+func TestContextAbortWithStatusJSONIndented(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.index = 4
+
+	c.AbortWithStatusJSONIndented(http.StatusBadRequest, testJSONAbortMsg{Foo: "fooValue", Bar: "barValue"})
+
+	assert.Equal(t, abortIndex == c.index, true)
+	assert.Equal(t, true, c.IsAborted())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "{\n    \"foo\": \"fooValue\",\n    \"bar\": \"barValue\"\n}", w.Body.String())
+}
+
+func TestContextAbortWithStatusXML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.index = 4
+
+	c.AbortWithStatusXML(http.StatusBadRequest, H{"foo": "bar"})
+
+	assert.Equal(t, abortIndex == c.index, true)
+	assert.Equal(t, true, c.IsAborted())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "<map><foo>bar</foo></map>", w.Body.String())
+}
+
+func TestContextSafeCall(t *testing.T) {
+	errBoom := errors.New("boom")
+	var errs []error
+	router := New()
+	router.GET("/", func(c *Context) {
+		errs = append(errs,
+			c.SafeCall(func() error { return nil }),
+			c.SafeCall(func() error { return errBoom }),
+			c.SafeCall(func() error { panic("oops") }),
+			c.SafeCall(func() error { panic(errBoom) }),
+		)
+		c.String(http.StatusOK, "still alive")
+	})
+
+	w := performRequest(router, "GET", "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "still alive", w.Body.String())
+	assert.Equal(t, 4, len(errs))
+	assert.Equal(t, nil, errs[0])
+	assert.Equal(t, errBoom, errs[1])
+	assert.Equal(t, "panic: oops", errs[2].Error())
+	assert.Equal(t, "panic: boom", errs[3].Error())
+	assert.Equal(t, true, errors.Is(errs[3], errBoom))
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.PanicMatches(t, func() {
+		c.SafeCall(func() error { panic(http.ErrAbortHandler) }) // nolint: errcheck
+	}, http.ErrAbortHandler.Error())
+}
+
+func TestContextShadow(t *testing.T) {
+	type shadowed struct {
+		body   string
+		query  string
+		header string
+	}
+	shadows := make(chan shadowed, 1)
+	router := New()
+	router.POST("/v2/users", func(c *Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(http.StatusTeapot, "ignored")
+		shadows <- shadowed{string(body), c.Query("page"), c.GetHeader("X-Test")}
+	})
+	router.POST("/v1/users", func(c *Context) {
+		c.Shadow("/v2/users")
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, string(body))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/users?page=2", bytes.NewBufferString(`{"name":"manu"}`))
+	req.Header.Set("X-Test", "shadowed")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"name":"manu"}`, w.Body.String())
+
+	select {
+	case shadow := <-shadows:
+		assert.Equal(t, shadowed{`{"name":"manu"}`, "2", "shadowed"}, shadow)
+	case <-time.After(time.Second):
+		t.Fatal("the shadow request was not handled")
+	}
+}
+
+func TestContextShadowPanic(t *testing.T) {
+	router := New()
+	router.GET("/panic", func(c *Context) {
+		panic("shadow failure")
+	})
+	router.GET("/", func(c *Context) {
+		c.Shadow("/panic")
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := performRequest(router, "GET", "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ok", w.Body.String())
+	router.WaitShadowRequests()
+}
+
+func TestContextRunCleanups(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	calls := 0
+	c.OnCleanup(func() { calls++ })
+	c.runCleanups()
+	c.runCleanups()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 0, len(c.cleanups))
+}
+
 func TestContextError(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	assert.Equal(t, 0, len(c.Errors))
@@ -1330,6 +1910,61 @@ func TestContextClientIP(t *testing.T) {
 	assert.Equal(t, 0, len(c.ClientIP()))
 }
 
+func TestContextClientIPRemoteIPHeaders(t *testing.T) {
+	c, router := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", nil)
+	c.Request.Header.Set("X-Forwarded-For", "20.20.20.20")
+	c.Request.Header.Set("CF-Connecting-IP", " 60.60.60.60 ")
+	c.Request.RemoteAddr = "40.40.40.40:42123"
+
+	router.RemoteIPHeaders = []string{"CF-Connecting-IP"}
+	assert.Equal(t, "60.60.60.60", c.ClientIP())
+
+	// invalid ips are skipped
+	router.RemoteIPHeaders = []string{"True-Client-IP", "CF-Connecting-IP"}
+	c.Request.Header.Set("True-Client-IP", "unknown")
+	assert.Equal(t, "60.60.60.60", c.ClientIP())
+
+	router.RemoteIPHeaders = nil
+	assert.Equal(t, "40.40.40.40", c.ClientIP())
+}
+
+func TestContextClientIPTrustedProxies(t *testing.T) {
+	c, router := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", nil)
+	c.Request.Header.Set("X-Real-IP", "10.10.10.10")
+	c.Request.Header.Set("X-Forwarded-For", "20.20.20.20, 30.30.30.30")
+	c.Request.RemoteAddr = "40.40.40.40:42123"
+
+	assert.Equal(t, nil, router.SetTrustedProxies([]string{"192.168.0.0/16", "10.0.0.1", "::1"}))
+
+	// untrusted remote address, spoofed headers are ignored
+	assert.Equal(t, "40.40.40.40", c.ClientIP())
+
+	// trusted proxy, the rightmost untrusted address is the client
+	c.Request.RemoteAddr = "192.168.1.1:42123"
+	assert.Equal(t, "30.30.30.30", c.ClientIP())
+
+	c.Request.Header.Set("X-Forwarded-For", "20.20.20.20, 10.0.0.1")
+	assert.Equal(t, "20.20.20.20", c.ClientIP())
+
+	c.Request.RemoteAddr = "[::1]:42123"
+	assert.Equal(t, "20.20.20.20", c.ClientIP())
+
+	c.Request.Header.Set("X-Forwarded-For", "garbage, 10.0.0.1")
+	assert.Equal(t, "10.10.10.10", c.ClientIP())
+
+	c.Request.Header.Del("X-Forwarded-For")
+	assert.Equal(t, "10.10.10.10", c.ClientIP())
+
+	// nobody is trusted
+	assert.Equal(t, nil, router.SetTrustedProxies(nil))
+	assert.Equal(t, "::1", c.ClientIP())
+
+	assert.NotEqual(t, nil, router.SetTrustedProxies([]string{"10.0.0.256"}))
+	assert.NotEqual(t, nil, router.SetTrustedProxies([]string{"10.0.0.0/33"}))
+}
+
 func TestContextContentType(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.Request, _ = http.NewRequest("POST", "/", nil)
@@ -1338,6 +1973,36 @@ func TestContextContentType(t *testing.T) {
 	assert.Equal(t, "application/json", c.ContentType())
 }
 
+func TestContextMultipartBoundary(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	assert.Equal(t, nil, mw.WriteField("foo", "bar"))
+	assert.Equal(t, nil, mw.Close())
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", body)
+	c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+	c.Request.Header.Set("Content-Length", strconv.Itoa(body.Len()))
+
+	boundary, ok := c.MultipartBoundary()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, mw.Boundary(), boundary)
+	assert.Equal(t, int64(body.Len()), c.ContentLength())
+
+	c.Request.Header.Set("Content-Type", "multipart/form-data")
+	_, ok = c.MultipartBoundary()
+	assert.Equal(t, false, ok)
+
+	c.Request.Header.Set("Content-Type", MIMEJSON+"; boundary=foo")
+	_, ok = c.MultipartBoundary()
+	assert.Equal(t, false, ok)
+
+	c.Request.Header.Del("Content-Length")
+	assert.Equal(t, int64(-1), c.ContentLength())
+	c.Request.Header.Set("Content-Length", "-5")
+	assert.Equal(t, int64(-1), c.ContentLength())
+}
+
 func TestContextAutoBindJSON(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("{\"foo\":\"bar\", \"bar\":\"foo\"}"))
@@ -1449,6 +2114,152 @@ func TestContextBadAutoBind(t *testing.T) {
 	assert.Equal(t, true, c.IsAborted())
 }
 
+func TestContextBindErrorType(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("{\"foo\":"))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	var obj struct {
+		Foo string `json:"foo"`
+	}
+
+	assert.NotEqual(t, nil, c.Bind(&obj))
+	assert.Equal(t, 1, len(c.Errors))
+	assert.Equal(t, 1, len(c.Errors.ByType(ErrorTypeBind)))
+	assert.Equal(t, 0, len(c.Errors.ByType(ErrorTypePublic|ErrorTypePrivate)))
+
+	c, _ = CreateTestContext(httptest.NewRecorder())
+	c.Params = Params{{Key: "id", Value: "abc"}}
+	var uri struct {
+		ID int `uri:"id"`
+	}
+	assert.NotEqual(t, nil, c.BindUri(&uri))
+	assert.Equal(t, ErrorTypeBind, c.Errors.Last().Type)
+}
+
+type failingValidator struct {
+	err error
+}
+
+func (v failingValidator) ValidateStruct(interface{}) error { return v.err }
+func (v failingValidator) Engine() interface{}              { return nil }
+
+func TestContextBindDebugPrintsField(t *testing.T) {
+	backup := binding.Validator
+	defer func() { binding.Validator = backup }()
+	binding.Validator = failingValidator{err: testValidationErrors{{"Foo"}}}
+
+	re := captureOutput(t, func() {
+		SetMode(DebugMode)
+		c, _ := CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"foo":"bar"}`))
+		c.Request.Header.Add("Content-Type", MIMEJSON)
+		var obj struct {
+			Foo string `json:"foo"`
+		}
+		assert.NotEqual(t, nil, c.Bind(&obj))
+		SetMode(TestMode)
+	})
+	Contains(t, re, "failed on Foo: validation failed")
+}
+
+type upperBinding struct{}
+
+func (upperBinding) Name() string { return "upper" }
+
+func (upperBinding) Bind(req *http.Request, obj interface{}) error {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	*obj.(*string) = strings.ToUpper(string(body))
+	return nil
+}
+
+func TestContextBindRegisteredBinding(t *testing.T) {
+	binding.Register("application/x-gin-upper", upperBinding{})
+	defer binding.Unregister("application/x-gin-upper")
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("gin"))
+	c.Request.Header.Add("Content-Type", "application/x-gin-upper; charset=utf-8")
+
+	var obj string
+	assert.Equal(t, nil, c.Bind(&obj))
+	assert.Equal(t, "GIN", obj)
+}
+
+func TestContextShouldBindPlain(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("hello"))
+	c.Request.Header.Add("Content-Type", MIMEPlain)
+
+	var s string
+	assert.Equal(t, nil, c.ShouldBindPlain(&s))
+	assert.Equal(t, "hello", s)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("hello"))
+	c.Request.Header.Add("Content-Type", MIMEPlain)
+	var b []byte
+	assert.Equal(t, nil, c.Bind(&b))
+	assert.Equal(t, []byte("hello"), b)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("hello"))
+	var obj struct {
+		Foo string
+	}
+	assert.NotEqual(t, nil, c.ShouldBindPlain(&obj))
+}
+
+func TestContextBindTextJSON(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"foo":"bar"}`))
+	c.Request.Header.Add("Content-Type", "text/json; charset=utf-8")
+	var obj struct {
+		Foo string `json:"foo"`
+	}
+
+	assert.Equal(t, nil, c.Bind(&obj))
+	assert.Equal(t, "bar", obj.Foo)
+
+	c.Request.Header.Set("Accept", "text/json")
+	assert.Equal(t, MIMEJSON, c.NegotiateFormat(MIMEXML, MIMEJSON))
+}
+
+func TestContextBindAndValidate(t *testing.T) {
+	backup := binding.Validator
+	defer func() { binding.Validator = backup }()
+
+	var obj struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"name":"gin","age":7}`))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	messages, ok := c.BindAndValidate(&obj)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, map[string]string(nil), messages)
+	assert.Equal(t, "gin", obj.Name)
+
+	binding.Validator = failingValidator{err: testValidationErrors{{"Name"}, {"Age"}}}
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"name":"","age":-1}`))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	messages, ok = c.BindAndValidate(&obj)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, map[string]string{"Name": "invalid Name", "Age": "invalid Age"}, messages)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"name":`))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	messages, ok = c.BindAndValidate(&obj)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, map[string]string{"error": "unexpected end of JSON input"}, messages)
+
+	assert.Equal(t, false, c.IsAborted())
+	assert.Equal(t, 0, len(c.Errors))
+	assert.Equal(t, false, c.Writer.Written())
+}
+
 func TestContextAutoShouldBindJSON(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("{\"foo\":\"bar\", \"bar\":\"foo\"}"))
@@ -1464,6 +2275,74 @@ func TestContextAutoShouldBindJSON(t *testing.T) {
 	assert.Equal(t, 0, len(c.Errors))
 }
 
+func TestContextShouldBindDefault(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("<root><foo>FOO</foo></root>"))
+	c.Request.Header.Add("Content-Type", MIMEXML)
+
+	var obj struct {
+		Foo string `xml:"foo"`
+	}
+	b, err := c.ShouldBindDefault(&obj)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, binding.XML, b)
+	assert.Equal(t, "FOO", obj.Foo)
+
+	c.Request, _ = http.NewRequest("POST", "/", bytes.NewBufferString("\"foo\":\"bar\"}"))
+	c.Request.Header.Add("Content-Type", MIMEJSON)
+	b, err = c.ShouldBindDefault(&obj)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, binding.JSON, b)
+	assert.Equal(t, false, c.IsAborted())
+	assert.Equal(t, false, c.Writer.Written())
+	assert.Equal(t, 0, len(w.Header()))
+}
+
+func TestContextShouldBindRequest(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("DELETE", "/users/1?force=true", nil)
+	c.Request.RemoteAddr = "10.10.10.10:1234"
+
+	var obj struct {
+		Method   string `req:"method"`
+		Path     string `req:"path"`
+		RemoteIP string `req:"remote_ip"`
+		Other    string
+	}
+	assert.Equal(t, nil, c.ShouldBindRequest(&obj))
+	assert.Equal(t, "DELETE", obj.Method)
+	assert.Equal(t, "/users/1", obj.Path)
+	assert.Equal(t, "10.10.10.10", obj.RemoteIP)
+	assert.Equal(t, "", obj.Other)
+}
+
+func TestContextShouldBindEmptyBody(t *testing.T) {
+	var obj struct {
+		Foo string `json:"foo"`
+	}
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	err := c.ShouldBindJSON(&obj)
+	assert.Equal(t, binding.ErrEmptyBody, err)
+	assert.Equal(t, true, errors.Is(err, io.EOF))
+
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	assert.Equal(t, binding.ErrEmptyBody, c.ShouldBindWith(&obj, binding.XML))
+
+	// the query is not a body binding
+	c.Request, _ = http.NewRequest("GET", "/?foo=bar", nil)
+	assert.Equal(t, nil, c.ShouldBindQuery(&obj))
+
+	w := httptest.NewRecorder()
+	c, _ = CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", nil)
+	assert.Equal(t, binding.ErrEmptyBody, c.BindJSON(&obj))
+	assert.Equal(t, true, c.IsAborted())
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestContextShouldBindWithJSON(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := CreateTestContext(w)
@@ -1652,6 +2531,61 @@ func TestContextGolangContext(t *testing.T) {
 	assert.Equal(t, nil, c.Value(1))
 }
 
+func TestContextHandlerTimeout(t *testing.T) {
+	router := New()
+	router.HandlerTimeout = 20 * time.Millisecond
+	router.GET("/", func(c *Context) {
+		_, ok := c.Deadline()
+		assert.Equal(t, true, ok)
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(time.Second):
+			t.Error("the request context was not cancelled")
+		}
+		assert.Equal(t, context.DeadlineExceeded, c.Err())
+		assert.Equal(t, c.Request.Context().Done(), c.Done())
+		c.Status(http.StatusServiceUnavailable)
+	})
+	var cp *Context
+	router.GET("/copy", func(c *Context) {
+		cp = c.Copy()
+	})
+
+	w := performRequest(router, "GET", "/")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	performRequest(router, "GET", "/copy")
+	_, ok := cp.Deadline()
+	assert.Equal(t, false, ok)
+	assert.Equal(t, true, cp.Done() == nil)
+	assert.Equal(t, nil, cp.Err())
+	assert.Equal(t, context.Canceled, cp.Request.Context().Err())
+}
+
+func TestContextNewRequest(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer downstream.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request = c.Request.WithContext(ctx)
+
+	req, err := c.NewRequest("GET", downstream.URL, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ctx, req.Context())
+
+	cancel()
+	_, err = http.DefaultClient.Do(req)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, true, errors.Is(err, context.Canceled))
+
+	_, err = c.NewRequest("bad method", downstream.URL, nil)
+	assert.NotEqual(t, nil, err)
+}
+
 func TestWebsocketsRequired(t *testing.T) {
 	// Example request from spec: https://tools.ietf.org/html/rfc6455#section-1.2
 	c, _ := CreateTestContext(httptest.NewRecorder())
@@ -1674,6 +2608,46 @@ func TestWebsocketsRequired(t *testing.T) {
 	assert.Equal(t, false, c.IsWebsocket())
 }
 
+func TestContextKeepAlive(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	assert.Equal(t, true, c.ProtoAtLeast(1, 1))
+	assert.Equal(t, false, c.ProtoAtLeast(2, 0))
+	assert.Equal(t, true, c.KeepAlive())
+
+	c.Request.Header.Set("Connection", "Upgrade, close")
+	assert.Equal(t, false, c.KeepAlive())
+
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Proto, c.Request.ProtoMajor, c.Request.ProtoMinor = "HTTP/1.0", 1, 0
+	assert.Equal(t, false, c.ProtoAtLeast(1, 1))
+	assert.Equal(t, false, c.KeepAlive())
+
+	c.Request.Header.Set("Connection", "Keep-Alive")
+	assert.Equal(t, true, c.KeepAlive())
+
+	c.Request.Close = true
+	assert.Equal(t, false, c.KeepAlive())
+}
+
+func TestContextClientCertificate(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	cert, ok := c.ClientCertificate()
+	assert.Equal(t, false, ok)
+	assert.Equal(t, (*x509.Certificate)(nil), cert)
+
+	c.Request.TLS = &tls.ConnectionState{}
+	_, ok = c.ClientCertificate()
+	assert.Equal(t, false, ok)
+
+	client := &x509.Certificate{Subject: pkix.Name{CommonName: "client.example.com"}}
+	c.Request.TLS.PeerCertificates = []*x509.Certificate{client, {}}
+	cert, ok = c.ClientCertificate()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "client.example.com", cert.Subject.CommonName)
+}
+
 func TestGetRequestHeaderValue(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	c.Request, _ = http.NewRequest("GET", "/chat", nil)
@@ -1683,6 +2657,16 @@ func TestGetRequestHeaderValue(t *testing.T) {
 	assert.Equal(t, 0, len(c.GetHeader("Connection")))
 }
 
+func TestGetRequestHeaderValues(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	c.Request.Header.Add("X-Forwarded-For", "10.0.0.1")
+	c.Request.Header.Add("x-forwarded-for", "10.0.0.2, 10.0.0.3")
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2, 10.0.0.3"}, c.GetHeaderValues("X-Forwarded-For"))
+	assert.Equal(t, 0, len(c.GetHeaderValues("Connection")))
+}
+
 func TestContextGetRawData(t *testing.T) {
 	c, _ := CreateTestContext(httptest.NewRecorder())
 	body := bytes.NewBufferString("Fetch binary post data")
@@ -1755,7 +2739,7 @@ func TestContextStream(t *testing.T) {
 	c, _ := CreateTestContext(w)
 
 	stopStream := true
-	c.Stream(func(w io.Writer) bool {
+	clientGone := c.Stream(func(w io.Writer) bool {
 		defer func() {
 			stopStream = false
 		}()
@@ -1766,6 +2750,7 @@ func TestContextStream(t *testing.T) {
 		return stopStream
 	})
 
+	assert.Equal(t, false, clientGone)
 	assert.Equal(t, "testtest", w.Body.String())
 }
 
@@ -1773,7 +2758,7 @@ func TestContextStreamWithClientGone(t *testing.T) {
 	w := CreateTestResponseRecorder()
 	c, _ := CreateTestContext(w)
 
-	c.Stream(func(writer io.Writer) bool {
+	clientGone := c.Stream(func(writer io.Writer) bool {
 		defer func() {
 			w.closeClient()
 		}()
@@ -1784,9 +2769,111 @@ func TestContextStreamWithClientGone(t *testing.T) {
 		return true
 	})
 
+	assert.Equal(t, true, clientGone)
 	assert.Equal(t, "test", w.Body.String())
 }
 
+func TestContextStreamReader(t *testing.T) {
+	w := CreateTestResponseRecorder()
+	c, _ := CreateTestContext(w)
+
+	clientGone := c.StreamReader(http.StatusCreated, "text/csv", strings.NewReader("id,name\n1,manu\n"))
+
+	assert.Equal(t, false, clientGone)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id,name\n1,manu\n", w.Body.String())
+	assert.Equal(t, 0, len(c.Errors))
+}
+
+func TestContextStreamReaderError(t *testing.T) {
+	w := CreateTestResponseRecorder()
+	c, _ := CreateTestContext(w)
+
+	reader := io.MultiReader(strings.NewReader("partial"), iotest.TimeoutReader(strings.NewReader("rest")))
+	c.StreamReader(http.StatusOK, "text/plain", iotest.OneByteReader(reader))
+
+	assert.Equal(t, "partialr", w.Body.String())
+	assert.Equal(t, 1, len(c.Errors))
+	assert.Equal(t, iotest.ErrTimeout, c.Errors.Last().Err)
+}
+
+func TestContextStreamReaderWithClientGone(t *testing.T) {
+	w := CreateTestResponseRecorder()
+	c, _ := CreateTestContext(w)
+	w.closeClient()
+
+	clientGone := c.StreamReader(http.StatusOK, "text/plain", strings.NewReader("test"))
+
+	assert.Equal(t, true, clientGone)
+	assert.Equal(t, "", w.Body.String())
+}
+
+func TestContextJSONStream(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	w := CreateTestResponseRecorder()
+	c, _ := CreateTestContext(w)
+
+	ch := make(chan interface{})
+	go func() {
+		for i, name := range []string{"a", "b", "c"} {
+			ch <- item{ID: i, Name: name}
+		}
+		close(ch)
+	}()
+	clientGone := c.JSONStream(http.StatusOK, ch)
+
+	assert.Equal(t, false, clientGone)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	var items []item
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &items))
+	assert.Equal(t, []item{{0, "a"}, {1, "b"}, {2, "c"}}, items)
+}
+
+func TestContextJSONStreamContentType(t *testing.T) {
+	ch := make(chan interface{})
+	close(ch)
+
+	w := CreateTestResponseRecorder()
+	c, router := CreateTestContext(w)
+	router.JSONOmitCharset = true
+	c.JSONStream(http.StatusOK, ch)
+	assert.Equal(t, MIMEJSON, w.Header().Get("Content-Type"))
+
+	w = CreateTestResponseRecorder()
+	c, _ = CreateTestContext(w)
+	c.Header("Content-Type", "application/vnd.api+json")
+	c.JSONStream(http.StatusOK, ch)
+	assert.Equal(t, "application/vnd.api+json", w.Header().Get("Content-Type"))
+}
+
+func TestContextJSONStreamEmpty(t *testing.T) {
+	w := CreateTestResponseRecorder()
+	c, _ := CreateTestContext(w)
+
+	ch := make(chan interface{})
+	close(ch)
+	c.JSONStream(http.StatusOK, ch)
+
+	assert.Equal(t, "[]", w.Body.String())
+}
+
+func TestContextJSONStreamError(t *testing.T) {
+	w := CreateTestResponseRecorder()
+	c, _ := CreateTestContext(w)
+
+	ch := make(chan interface{}, 2)
+	ch <- 1
+	ch <- make(chan int)
+	c.JSONStream(http.StatusOK, ch)
+
+	assert.Equal(t, "[1]", w.Body.String())
+	assert.Equal(t, 1, len(c.Errors))
+}
+
 func TestContextResetInHandler(t *testing.T) {
 	w := CreateTestResponseRecorder()
 	c, _ := CreateTestContext(w)
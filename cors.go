@@ -0,0 +1,133 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig defines the Cross-Origin Resource Sharing policy of the CORS middleware.
+type CORSConfig struct {
+	// AllowOrigins lists the origins allowed to make cross-origin requests,
+	// e.g. "https://example.com". "*" allows any origin and a single "*" inside
+	// an origin matches a part of it, e.g. "https://*.example.com".
+	AllowOrigins []string
+
+	// AllowMethods lists the methods allowed by the preflight requests,
+	// it defaults to GET, POST, PUT, PATCH, DELETE and HEAD.
+	AllowMethods []string
+
+	// AllowHeaders lists the request headers allowed by the preflight requests,
+	// it defaults to Origin, Content-Length and Content-Type.
+	AllowHeaders []string
+
+	// ExposeHeaders lists the response headers the browsers let the clients read.
+	ExposeHeaders []string
+
+	// AllowCredentials allows the requests with cookies or HTTP authentication,
+	// it can not be used with the "*" origin.
+	AllowCredentials bool
+
+	// MaxAge is how long the browsers may cache the result of a preflight request.
+	MaxAge time.Duration
+}
+
+// CORS returns a Cross-Origin Resource Sharing middleware, it should be added to the engine
+// so the preflight requests are answered for every route, e.g. router.Use(gin.CORS(config)).
+// The preflight requests are answered with 204 and the headers of the policy, the other
+// cross-origin requests get the Access-Control-Allow-* headers and reach the handlers.
+// The requests from an origin that is not allowed are aborted with 403, except the
+// same-origin requests, e.g. a form posted by a page of the server, which are left untouched.
+func CORS(config CORSConfig) HandlerFunc {
+	assert1(len(config.AllowOrigins) > 0, "CORS: AllowOrigins can not be empty")
+	anyOrigin := false
+	for _, origin := range config.AllowOrigins {
+		anyOrigin = anyOrigin || origin == "*"
+		assert1(strings.Count(origin, "*") <= 1, "CORS: an origin can hold a single wildcard: "+origin)
+	}
+	assert1(!anyOrigin || !config.AllowCredentials, "CORS: AllowCredentials can not be used with the \"*\" origin")
+
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodHead}
+	}
+	if len(config.AllowHeaders) == 0 {
+		config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type"}
+	}
+	allowMethods := strings.ToUpper(strings.Join(config.AllowMethods, ", "))
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := strconv.FormatInt(int64(config.MaxAge/time.Second), 10)
+
+	return func(c *Context) {
+		origin := c.requestHeader("Origin")
+		if origin == "" || isSameOrigin(c.Request, origin) {
+			// not a cross-origin request
+			return
+		}
+		header := c.Writer.Header()
+		if !anyOrigin {
+			header.Add("Vary", "Origin")
+		}
+		if !anyOrigin && !config.allowOrigin(origin) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		if anyOrigin {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if c.Request.Method == http.MethodOptions && c.requestHeader("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+			if config.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		if exposeHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
+	}
+}
+
+func (config *CORSConfig) allowOrigin(origin string) bool {
+	for _, allowed := range config.AllowOrigins {
+		if i := strings.IndexByte(allowed, '*'); i >= 0 {
+			prefix, suffix := allowed[:i], allowed[i+1:]
+			if len(origin) >= len(prefix)+len(suffix) &&
+				strings.EqualFold(origin[:len(prefix)], prefix) &&
+				strings.EqualFold(origin[len(origin)-len(suffix):], suffix) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(origin, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSameOrigin reports whether origin is the origin of the server handling req.
+func isSameOrigin(req *http.Request, origin string) bool {
+	scheme := "http://"
+	if req.TLS != nil {
+		scheme = "https://"
+	}
+	return strings.EqualFold(origin, scheme+req.Host)
+}
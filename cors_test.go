@@ -0,0 +1,95 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert"
+)
+
+func newCORSRouter(config CORSConfig) *Engine {
+	router := New()
+	router.Use(CORS(config))
+	router.GET("/users", func(c *Context) {
+		c.String(http.StatusOK, "users")
+	})
+	return router
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := newCORSRouter(CORSConfig{
+		AllowOrigins:     []string{"https://example.com", "https://*.example.org"},
+		AllowMethods:     []string{"GET", "PUT"},
+		AllowHeaders:     []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+
+	w := performRequest(router, http.MethodOptions, "/users",
+		header{Key: "Origin", Value: "https://api.example.org"},
+		header{Key: "Access-Control-Request-Method", Value: "PUT"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "", w.Body.String())
+	assert.Equal(t, "https://api.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, PUT", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, w.Header().Values("Vary"))
+
+	w = performRequest(router, http.MethodOptions, "/users",
+		header{Key: "Origin", Value: "https://evil.com"},
+		header{Key: "Access-Control-Request-Method", Value: "PUT"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSSimpleRequest(t *testing.T) {
+	router := newCORSRouter(CORSConfig{
+		AllowOrigins:  []string{"*"},
+		ExposeHeaders: []string{"X-Total-Count"},
+	})
+
+	w := performRequest(router, http.MethodGet, "/users", header{Key: "Origin", Value: "https://example.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "users", w.Body.String())
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Total-Count", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "", w.Header().Get("Vary"))
+
+	// same origin
+	w = performRequest(router, http.MethodGet, "/users")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSSameOrigin(t *testing.T) {
+	router := newCORSRouter(CORSConfig{AllowOrigins: []string{"https://example.org"}})
+	router.POST("/users", func(c *Context) {
+		c.String(http.StatusCreated, "created")
+	})
+
+	// httptest requests are sent to example.com
+	w := performRequest(router, http.MethodPost, "/users", header{Key: "Origin", Value: "http://example.com"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "created", w.Body.String())
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "", w.Header().Get("Vary"))
+
+	w = performRequest(router, http.MethodPost, "/users", header{Key: "Origin", Value: "https://example.com"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCORSConfigPanics(t *testing.T) {
+	Panics(t, func() { CORS(CORSConfig{}) })
+	Panics(t, func() { CORS(CORSConfig{AllowOrigins: []string{"https://*.*.example.com"}}) })
+	assert.PanicMatches(t, func() {
+		CORS(CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true})
+	}, `CORS: AllowCredentials can not be used with the "*" origin`)
+}
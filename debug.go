@@ -5,8 +5,10 @@
 package gin
 
 import (
+	"expvar"
 	"fmt"
 	"html/template"
+	"net/http"
 	"runtime"
 	"strconv"
 	"strings"
@@ -23,6 +25,24 @@ func IsDebugging() bool {
 // DebugPrintRouteFunc indicates debug log output format.
 var DebugPrintRouteFunc func(httpMethod, absolutePath, handlerName string, nuHandlers int)
 
+// RegisterDebugRoutes mounts GET {prefix}/routes, which lists the registered routes as JSON.
+// It only does so in debug mode, so it can be left in place for release builds.
+func (engine *Engine) RegisterDebugRoutes(prefix string) {
+	if !IsDebugging() {
+		return
+	}
+	engine.GET(joinPaths(prefix, "/routes"), func(c *Context) {
+		c.JSON(http.StatusOK, engine.Routes())
+	})
+}
+
+// RegisterExpvar mounts the expvar handler at the given path, exposing the published
+// variables, such as memstats and cmdline, as JSON.
+// Note that the expvar package also serves them at /debug/vars on http.DefaultServeMux.
+func (engine *Engine) RegisterExpvar(path string) {
+	engine.GET(path, WrapH(expvar.Handler()))
+}
+
 func debugPrintRoute(httpMethod, absolutePath string, handlers HandlersChain) {
 	if IsDebugging() {
 		nuHandlers := len(handlers)
@@ -49,13 +69,19 @@ func debugPrintLoadTemplate(tmpl *template.Template) {
 
 func debugPrint(format string, values ...interface{}) {
 	if IsDebugging() {
-		if !strings.HasSuffix(format, "\n") {
-			format += "\n"
-		}
-		fmt.Fprintf(DefaultWriter, "[GIN-debug] "+format, values...)
+		writeDebug(format, values...)
 	}
 }
 
+// writeDebug writes a debug message whatever the mode, for the callers which
+// checked it earlier, e.g. from a goroutine.
+func writeDebug(format string, values ...interface{}) {
+	if !strings.HasSuffix(format, "\n") {
+		format += "\n"
+	}
+	fmt.Fprintf(DefaultWriter, "[GIN-debug] "+format, values...)
+}
+
 func getMinVer(v string) (uint64, error) {
 	first := strings.IndexByte(v, '.')
 	last := strings.LastIndexByte(v, '.')
@@ -94,6 +120,22 @@ at initialization. ie. before any route is registered or the router is listening
 `)
 }
 
+func debugPrintBindError(obj interface{}, err error) {
+	if !IsDebugging() {
+		return
+	}
+
+	var fields []string
+	for _, fe := range fieldErrors(err) {
+		fields = append(fields, fe.Field())
+	}
+	if len(fields) == 0 {
+		debugPrint("[WARNING] Binding %T failed: %v\n", obj, err)
+		return
+	}
+	debugPrint("[WARNING] Binding %T failed on %s: %v\n", obj, strings.Join(fields, ", "), err)
+}
+
 func debugPrintError(err error) {
 	if err != nil {
 		if IsDebugging() {
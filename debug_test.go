@@ -6,10 +6,12 @@ package gin
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"html/template"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"runtime"
 	"sync"
@@ -55,6 +57,36 @@ func TestDebugPrintError(t *testing.T) {
 	assert.Equal(t, "[GIN-debug] [ERROR] this is an error\n", re)
 }
 
+type testFieldError struct {
+	field string
+}
+
+func (e testFieldError) Field() string { return e.field }
+func (e testFieldError) Error() string { return "invalid " + e.field }
+
+type testValidationErrors []testFieldError
+
+func (e testValidationErrors) Error() string { return "validation failed" }
+
+func TestDebugPrintBindError(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+	re := captureOutput(t, func() {
+		SetMode(TestMode)
+		debugPrintBindError(&user{}, testFieldError{"Name"})
+		SetMode(DebugMode)
+		debugPrintBindError(&user{}, testFieldError{"Name"})
+		debugPrintBindError(&user{}, testValidationErrors{{"Name"}, {"Age"}})
+		debugPrintBindError(&user{}, errors.New("unexpected EOF"))
+		SetMode(TestMode)
+	})
+	assert.Equal(t, "[GIN-debug] [WARNING] Binding *gin.user failed on Name: invalid Name\n"+
+		"[GIN-debug] [WARNING] Binding *gin.user failed on Name, Age: validation failed\n"+
+		"[GIN-debug] [WARNING] Binding *gin.user failed: unexpected EOF\n", re)
+}
+
 func TestDebugPrintRoutes(t *testing.T) {
 	re := captureOutput(t, func() {
 		SetMode(DebugMode)
@@ -64,6 +96,51 @@ func TestDebugPrintRoutes(t *testing.T) {
 	assert.MatchRegex(t, re, `^\[GIN-debug\] GET    /path/to/route/:param     --> (.*/vendor/)?github.com/manucorporat/gin-diet.handlerNameTest \(2 handlers\)\n$`)
 }
 
+func TestRegisterDebugRoutes(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", handlerNameTest)
+	captureOutput(t, func() {
+		SetMode(DebugMode)
+		router.RegisterDebugRoutes("/_debug")
+		SetMode(TestMode)
+	})
+
+	w := performRequest(router, "GET", "/_debug/routes")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var routes []map[string]string
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &routes))
+	assert.Equal(t, 2, len(routes))
+	for _, route := range routes {
+		if route["path"] == "/users/:id" {
+			assert.Equal(t, "GET", route["method"])
+			assert.MatchRegex(t, route["handler"], `^(.*/vendor/)?github.com/manucorporat/gin-diet.handlerNameTest$`)
+		} else {
+			assert.Equal(t, "/_debug/routes", route["path"])
+		}
+	}
+
+	router = New()
+	SetMode(ReleaseMode)
+	router.RegisterDebugRoutes("/_debug")
+	SetMode(TestMode)
+	w = performRequest(router, "GET", "/_debug/routes")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, 0, len(router.Routes()))
+}
+
+func TestRegisterExpvar(t *testing.T) {
+	router := New()
+	router.RegisterExpvar("/metrics/vars")
+
+	w := performRequest(router, "GET", "/metrics/vars")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	var vars map[string]interface{}
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &vars))
+	_, ok := vars["memstats"]
+	assert.Equal(t, true, ok)
+}
+
 func TestDebugPrintLoadTemplate(t *testing.T) {
 	re := captureOutput(t, func() {
 		SetMode(DebugMode)
@@ -0,0 +1,59 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// maxEchoBody is the number of body bytes sent back by EchoHandler.
+const maxEchoBody = 1 << 20
+
+// echoRedactedHeaders are the request headers whose values EchoHandler hides.
+var echoRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// EchoHandler returns a handler sending back the request it receives as JSON, its method,
+// path, query, headers and body, to debug the proxies in front of the server, e.g.
+// router.Any("/debug/echo", gin.EchoHandler()). The values of the Authorization,
+// Proxy-Authorization, Cookie and X-Api-Key headers are redacted and only the first
+// MiB of the body is sent back. It should not be exposed in production.
+func EchoHandler() HandlerFunc {
+	return func(c *Context) {
+		headers := make(http.Header, len(c.Request.Header))
+		for key, values := range c.Request.Header {
+			headers[key] = values
+		}
+		for _, key := range echoRedactedHeaders {
+			if values := headers.Values(key); len(values) > 0 {
+				redacted := make([]string, len(values))
+				for i := range redacted {
+					redacted[i] = "[REDACTED]"
+				}
+				headers[http.CanonicalHeaderKey(key)] = redacted
+			}
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = ioutil.ReadAll(io.LimitReader(c.Request.Body, maxEchoBody)); err != nil {
+				c.Error(err) // nolint: errcheck
+			}
+		}
+
+		c.JSON(http.StatusOK, H{
+			"method":   c.Request.Method,
+			"path":     c.Request.URL.Path,
+			"query":    c.Request.URL.RawQuery,
+			"proto":    c.Request.Proto,
+			"host":     c.Request.Host,
+			"clientIP": c.ClientIP(),
+			"headers":  headers,
+			"body":     string(body),
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestEchoHandler(t *testing.T) {
+	router := New()
+	router.Any("/debug/echo", EchoHandler())
+
+	req, _ := http.NewRequest("POST", "/debug/echo?verbose=1", strings.NewReader(`{"name":"gin"}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Cookie", "session=secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var echo struct {
+		Method  string
+		Path    string
+		Query   string
+		Headers http.Header
+		Body    string
+	}
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &echo))
+	assert.Equal(t, "POST", echo.Method)
+	assert.Equal(t, "/debug/echo", echo.Path)
+	assert.Equal(t, "verbose=1", echo.Query)
+	assert.Equal(t, `{"name":"gin"}`, echo.Body)
+	assert.Equal(t, []string{MIMEJSON}, echo.Headers["Content-Type"])
+	assert.Equal(t, []string{"[REDACTED]"}, echo.Headers["Authorization"])
+	assert.Equal(t, []string{"[REDACTED]"}, echo.Headers["Cookie"])
+	assert.Equal(t, false, strings.Contains(w.Body.String(), "secret"))
+	// the request headers are left untouched
+	assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// Envelope returns a middleware that wraps the application/json responses of the
+// handlers after it: {"data": ...} for the successful ones and {"error": ...} when
+// the status is 400 or above. Other responses are written untouched.
+// The response is buffered until the handlers are done, so it can't be streamed.
+func Envelope() HandlerFunc {
+	return func(c *Context) {
+		w := &envelopeWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+
+		c.Writer = w.ResponseWriter
+		if !w.written {
+			return
+		}
+		body := w.body.Bytes()
+		if len(body) > 0 && filterFlags(w.Header().Get("Content-Type")) == MIMEJSON {
+			key := "data"
+			if w.Status() >= http.StatusBadRequest {
+				key = "error"
+			}
+			body = append(append([]byte(`{"`+key+`":`), bytes.TrimSpace(body)...), '}')
+			w.Header().Del("Content-Length")
+		}
+		if len(body) == 0 {
+			c.Writer.WriteHeaderNow()
+			return
+		}
+		if _, err := c.Writer.Write(body); err != nil {
+			debugPrint("cannot write the envelope: %v", err)
+		}
+	}
+}
+
+// envelopeWriter holds the response body back until Envelope writes it.
+type envelopeWriter struct {
+	ResponseWriter
+	body    bytes.Buffer
+	written bool
+}
+
+func (w *envelopeWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *envelopeWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *envelopeWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *envelopeWriter) Written() bool {
+	return w.written
+}
+
+func (w *envelopeWriter) Size() int {
+	if !w.written {
+		return noWritten
+	}
+	return w.body.Len()
+}
+
+func (w *envelopeWriter) Flush() {}
@@ -0,0 +1,72 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestEnvelope(t *testing.T) {
+	router := New()
+	router.Use(Envelope())
+	router.GET("/user", func(c *Context) {
+		c.JSON(http.StatusOK, H{"name": "manu"})
+	})
+	router.GET("/users", func(c *Context) {
+		c.IndentedJSON(http.StatusOK, []string{"manu"})
+	})
+	router.POST("/user", func(c *Context) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, H{"message": "invalid name"})
+	})
+	router.GET("/text", func(c *Context) {
+		c.String(http.StatusOK, "plain")
+	})
+	router.GET("/problem", func(c *Context) {
+		c.Problem(http.StatusNotFound, ProblemDetails{Title: "missing"})
+	})
+	router.DELETE("/user", func(c *Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := performRequest(router, "GET", "/user")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"data":{"name":"manu"}}`, w.Body.String())
+
+	w = performRequest(router, "GET", "/users")
+	assert.Equal(t, "{\"data\":[\n    \"manu\"\n]}", w.Body.String())
+
+	w = performRequest(router, "POST", "/user")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"error":{"message":"invalid name"}}`, w.Body.String())
+
+	w = performRequest(router, "GET", "/text")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "plain", w.Body.String())
+
+	w = performRequest(router, "GET", "/problem")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"title":"missing","status":404}`, w.Body.String())
+
+	w = performRequest(router, "DELETE", "/user")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "", w.Body.String())
+}
+
+func TestEnvelopeWithPanic(t *testing.T) {
+	router := New()
+	router.Use(RecoveryWithWriter(nil), Envelope())
+	router.GET("/", func(c *Context) {
+		c.JSON(http.StatusOK, H{"partial": true})
+		panic("oops")
+	})
+
+	w := performRequest(router, "GET", "/")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "", w.Body.String())
+}
@@ -134,6 +134,25 @@ func (a errorMsgs) Errors() []string {
 	return errorStrings
 }
 
+// Map returns the message, the type and the meta data of each error, for a custom report.
+// Example:
+// 		c.Error(errors.New("first")).SetType(gin.ErrorTypePublic).SetMeta("some data")
+// 		c.Errors.Map() // == []map[string]interface{}{{"message": "first", "type": gin.ErrorTypePublic, "meta": "some data"}}
+func (a errorMsgs) Map() []map[string]interface{} {
+	if len(a) == 0 {
+		return nil
+	}
+	maps := make([]map[string]interface{}, len(a))
+	for i, msg := range a {
+		maps[i] = map[string]interface{}{
+			"message": msg.Error(),
+			"type":    msg.Type,
+			"meta":    msg.Meta,
+		}
+	}
+	return maps
+}
+
 func (a errorMsgs) JSON() interface{} {
 	switch len(a) {
 	case 0:
@@ -167,3 +186,27 @@ func (a errorMsgs) String() string {
 	}
 	return buffer.String()
 }
+
+// fieldError is implemented by the errors of the validators reporting the failed field,
+// e.g. the go-playground/validator FieldError.
+type fieldError interface {
+	error
+	Field() string
+}
+
+// fieldErrors returns the field errors held by err, either a fieldError or a slice
+// of them such as validator.ValidationErrors.
+func fieldErrors(err error) []fieldError {
+	if fe, ok := err.(fieldError); ok {
+		return []fieldError{fe}
+	}
+	var errs []fieldError
+	if v := reflect.ValueOf(err); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if fe, ok := v.Index(i).Interface().(fieldError); ok {
+				errs = append(errs, fe)
+			}
+		}
+	}
+	return errs
+}
@@ -104,3 +104,18 @@ Error #03: third
 	assert.Equal(t, nil, errs.JSON())
 	assert.Equal(t, 0, len(errs.String()))
 }
+
+func TestErrorSliceMap(t *testing.T) {
+	errs := errorMsgs{
+		{Err: errors.New("first"), Type: ErrorTypePrivate},
+		{Err: errors.New("second"), Type: ErrorTypeBind, Meta: "some data"},
+		{Err: errors.New("third"), Type: ErrorTypePublic, Meta: H{"status": "400"}},
+	}
+
+	assert.Equal(t, []map[string]interface{}{
+		{"message": "first", "type": ErrorTypePrivate, "meta": nil},
+		{"message": "second", "type": ErrorTypeBind, "meta": "some data"},
+		{"message": "third", "type": ErrorTypePublic, "meta": H{"status": "400"}},
+	}, errs.Map())
+	assert.Equal(t, []map[string]interface{}(nil), errorMsgs{}.Map())
+}
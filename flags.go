@@ -0,0 +1,25 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+// FeatureFlagsKey is the context key holding the flags resolved by the FeatureFlags middleware.
+const FeatureFlagsKey = "_gin-gonic/gin/featureflagskey"
+
+// FeatureFlags returns a middleware resolving the feature flags once per request,
+// e.g. from a cookie for A/B testing, the handlers read them with c.Flag.
+func FeatureFlags(resolver func(*Context) map[string]bool) HandlerFunc {
+	assert1(resolver != nil, "FeatureFlags: resolver can not be nil")
+	return func(c *Context) {
+		c.Set(FeatureFlagsKey, resolver(c))
+	}
+}
+
+// Flag returns the value of the feature flag resolved by the FeatureFlags middleware,
+// false when the flag or the middleware is missing.
+func (c *Context) Flag(name string) bool {
+	value, _ := c.Get(FeatureFlagsKey)
+	flags, _ := value.(map[string]bool)
+	return flags[name]
+}
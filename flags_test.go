@@ -0,0 +1,40 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	resolved := 0
+	router := New()
+	router.Use(FeatureFlags(func(c *Context) map[string]bool {
+		resolved++
+		return map[string]bool{"new-checkout": c.Query("group") == "b", "dark-mode": true}
+	}))
+	router.GET("/", func(c *Context) {
+		c.JSON(http.StatusOK, H{
+			"checkout": c.Flag("new-checkout"),
+			"dark":     c.Flag("dark-mode"),
+			"unknown":  c.Flag("unknown"),
+		})
+	})
+
+	w := performRequest(router, http.MethodGet, "/?group=b")
+	assert.Equal(t, `{"checkout":true,"dark":true,"unknown":false}`, w.Body.String())
+	w = performRequest(router, http.MethodGet, "/?group=a")
+	assert.Equal(t, `{"checkout":false,"dark":true,"unknown":false}`, w.Body.String())
+	assert.Equal(t, 2, resolved)
+
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, false, c.Flag("dark-mode"))
+
+	Panics(t, func() { FeatureFlags(nil) })
+}
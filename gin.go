@@ -5,23 +5,34 @@
 package gin
 
 import (
+	"context"
 	"fmt"
 	"html/template"
+	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"os"
 	"path"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/manucorporat/gin-diet/internal/bytesconv"
 	"github.com/manucorporat/gin-diet/render"
 )
 
-const defaultMultipartMemory = 32 << 20 // 32 MB
+const (
+	defaultMultipartMemory = 32 << 20  // 32 MB
+	defaultMaxDrainBytes   = 256 << 10 // 256 KB
+)
 
 var (
 	default404Body   = []byte("404 page not found")
 	default405Body   = []byte("405 method not allowed")
+	default503Body   = []byte("503 service unavailable")
 	defaultAppEngine bool
 )
 
@@ -41,15 +52,28 @@ func (c HandlersChain) Last() HandlerFunc {
 
 // RouteInfo represents a request route's specification which contains method and path and its handler.
 type RouteInfo struct {
-	Method      string
-	Path        string
-	Handler     string
-	HandlerFunc HandlerFunc
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Handler     string      `json:"handler"`
+	HandlerFunc HandlerFunc `json:"-"`
+	Meta        H           `json:"-"`
 }
 
 // RoutesInfo defines a RouteInfo array.
 type RoutesInfo []RouteInfo
 
+// routeKey identifies a registered route by its method and full path.
+type routeKey struct {
+	method string
+	path   string
+}
+
+// groupNoRoute holds the NoRoute handlers of a group.
+type groupNoRoute struct {
+	prefix   string
+	handlers HandlersChain
+}
+
 // Engine is the framework's instance, it contains the muxer, middleware and configuration settings.
 // Create an instance of Engine, by using New() or Default()
 type Engine struct {
@@ -82,6 +106,10 @@ type Engine struct {
 	HandleMethodNotAllowed bool
 	ForwardedByClientIP    bool
 
+	// RemoteIPHeaders are the headers Context.ClientIP reads the client ip from, in order,
+	// when ForwardedByClientIP is enabled, e.g. []string{"CF-Connecting-IP"}.
+	RemoteIPHeaders []string
+
 	// #726 #755 If enabled, it will thrust some headers starting with
 	// 'X-AppEngine...' for better integration with that PaaS.
 	AppEngine bool
@@ -98,10 +126,54 @@ type Engine struct {
 	// method call.
 	MaxMultipartMemory int64
 
+	// MaxConcurrentMultipart, when not zero, limits how many multipart requests are
+	// handled at the same time, as MaxMultipartMemory only bounds each of them.
+	// The requests beyond the limit are answered with 503 Service Unavailable,
+	// after the engine middleware, e.g. the logger.
+	MaxConcurrentMultipart int
+
 	// RemoveExtraSlash a parameter can be parsed from the URL even with extra slashes.
 	// See the PR #1817 and issue #1644
 	RemoveExtraSlash bool
 
+	// If enabled, in debug mode the JSON responses of the routes registered with
+	// a ResponseSchemaKey metadata are validated against that schema and the
+	// mismatches are logged, the responses are left untouched.
+	ValidateResponseSchemas bool
+
+	// If enabled, the request body left unread by an aborted handlers chain is
+	// drained, so the client can reuse the connection after an early rejection,
+	// e.g. a failed authentication.
+	DrainBodyOnAbort bool
+
+	// MaxDrainBytes bounds the bodies drained by DrainBodyOnAbort, 256 KB by default.
+	// A longer body is left unread and the response gets a "Connection: close" header
+	// when the chain is aborted before it is written, e.g. with AbortWithStatus.
+	// net/http itself discards up to 256 KB of an unread body, a larger MaxDrainBytes
+	// keeps the connection alive after rejecting the longer bodies too.
+	MaxDrainBytes int64
+
+	// DefaultDataContentType is the Content-Type used by Context.Data when it is
+	// given an empty one, e.g. "application/octet-stream".
+	DefaultDataContentType string
+
+	// JSONOmitCharset makes the JSON responses use a bare "application/json"
+	// Content-Type, without the "; charset=utf-8" parameter.
+	JSONOmitCharset bool
+
+	// JSONKeyTransform, when set, renames the object keys of the JSON responses,
+	// e.g. CamelCaseKey or SnakeCaseKey, of every JSON method of Context but
+	// Problem. Every key is renamed, the tagged fields and the map keys too.
+	// The response is encoded then decoded and encoded again, so it costs
+	// about three times a plain JSON response.
+	JSONKeyTransform func(string) string
+
+	// HandlerTimeout, when not zero, bounds the context of each request with this deadline,
+	// so Request.Context() and the Context itself (Deadline, Done and Err) are cancelled
+	// once it expires. The handlers must watch Done, they are not interrupted.
+	// The contexts returned by Context.Copy are not bound by it.
+	HandlerTimeout time.Duration
+
 	delims           render.Delims
 	secureJsonPrefix string
 	HTMLRender       render.HTMLRender
@@ -112,6 +184,18 @@ type Engine struct {
 	noMethod         HandlersChain
 	pool             sync.Pool
 	trees            methodTrees
+	routesMeta       map[routeKey]H
+	trailingSlash    map[routeKey]bool
+	groupNoRoutes    []groupNoRoute
+	responseSchemas  sync.Map
+	// number of engine middleware at the head of each route chain, see Rebuild.
+	chainGlobals map[*HandlerFunc]int
+	// nil trusts every proxy, see SetTrustedProxies.
+	trustedCIDRs []*net.IPNet
+	// multipart requests being handled, see MaxConcurrentMultipart.
+	multipartActive int32
+	// shadow requests being handled, see Context.Shadow.
+	shadows sync.WaitGroup
 }
 
 var _ IRouter = &Engine{}
@@ -122,6 +206,7 @@ var _ IRouter = &Engine{}
 // - RedirectFixedPath:      false
 // - HandleMethodNotAllowed: false
 // - ForwardedByClientIP:    true
+// - RemoteIPHeaders:        X-Forwarded-For, X-Real-IP
 // - UseRawPath:             false
 // - UnescapePathValues:     true
 func New() *Engine {
@@ -137,11 +222,13 @@ func New() *Engine {
 		RedirectFixedPath:      false,
 		HandleMethodNotAllowed: false,
 		ForwardedByClientIP:    true,
+		RemoteIPHeaders:        []string{"X-Forwarded-For", "X-Real-IP"},
 		AppEngine:              defaultAppEngine,
 		UseRawPath:             false,
 		RemoveExtraSlash:       false,
 		UnescapePathValues:     true,
 		MaxMultipartMemory:     defaultMultipartMemory,
+		MaxDrainBytes:          defaultMaxDrainBytes,
 		trees:                  make(methodTrees, 0, 9),
 		delims:                 render.Delims{Left: "{{", Right: "}}"},
 		secureJsonPrefix:       "while(1);",
@@ -177,6 +264,50 @@ func (engine *Engine) SecureJsonPrefix(prefix string) *Engine {
 	return engine
 }
 
+// SetTrustedProxies sets the proxies, given as IPs or CIDRs, whose X-Forwarded-For and
+// X-Real-Ip headers are honored by Context.ClientIP, e.g. []string{"10.0.0.0/8", "192.168.1.1"}.
+// The headers of the requests coming from other addresses are ignored, an empty list
+// ignores them for every request. By default every proxy is trusted.
+func (engine *Engine) SetTrustedProxies(trustedProxies []string) error {
+	cidrs := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return &net.ParseError{Type: "IP address", Text: proxy}
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return err
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	engine.trustedCIDRs = cidrs
+	return nil
+}
+
+// isTrustedProxy reports whether the forwarding headers set by ip can be trusted.
+func (engine *Engine) isTrustedProxy(ip net.IP) bool {
+	if engine.trustedCIDRs == nil {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range engine.trustedCIDRs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadHTMLGlob loads HTML files identified by glob pattern
 // and associates the result with HTML renderer.
 func (engine *Engine) LoadHTMLGlob(pattern string) {
@@ -225,12 +356,26 @@ func (engine *Engine) NoRoute(handlers ...HandlerFunc) {
 	engine.rebuild404Handlers()
 }
 
-// NoMethod sets the handlers called when... TODO.
+// NoMethod adds handlers for NoMethod, called when HandleMethodNotAllowed is enabled
+// and the path is only registered for other methods. It returns a 405 code by default
+// and the Allow header lists the methods registered for the path.
 func (engine *Engine) NoMethod(handlers ...HandlerFunc) {
 	engine.noMethod = handlers
 	engine.rebuild405Handlers()
 }
 
+// NegotiatedNoRoute returns a handler for NoRoute and NoMethod answering the clients
+// preferring JSON with a JSON body, e.g. {"error":"not found"}, instead of the plain text one:
+// router.NoRoute(gin.NegotiatedNoRoute()).
+func NegotiatedNoRoute() HandlerFunc {
+	return func(c *Context) {
+		if c.NegotiateFormat(MIMEPlain, MIMEJSON) == MIMEJSON {
+			code := c.Writer.Status()
+			c.JSON(code, H{"error": strings.ToLower(http.StatusText(code))})
+		}
+	}
+}
+
 // Use attaches a global middleware to the router. ie. the middleware attached though Use() will be
 // included in the handlers chain for every single request. Even 404, 405, static files...
 // For example, this is the right place for a logger or error management middleware.
@@ -241,6 +386,32 @@ func (engine *Engine) Use(middleware ...HandlerFunc) IRoutes {
 	return engine
 }
 
+func (engine *Engine) addGroupNoRoute(prefix string, handlers HandlersChain) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	for i := range engine.groupNoRoutes {
+		if engine.groupNoRoutes[i].prefix == prefix {
+			engine.groupNoRoutes[i].handlers = handlers
+			return
+		}
+	}
+	engine.groupNoRoutes = append(engine.groupNoRoutes, groupNoRoute{prefix: prefix, handlers: handlers})
+	// the longest prefix wins
+	sort.SliceStable(engine.groupNoRoutes, func(i, j int) bool {
+		return len(engine.groupNoRoutes[i].prefix) > len(engine.groupNoRoutes[j].prefix)
+	})
+}
+
+// noRouteHandlers returns the NoRoute handlers of the innermost group containing path,
+// or the engine ones.
+func (engine *Engine) noRouteHandlers(path string) HandlersChain {
+	for _, group := range engine.groupNoRoutes {
+		if strings.HasPrefix(path, group.prefix) && (len(path) == len(group.prefix) || path[len(group.prefix)] == '/') {
+			return group.handlers
+		}
+	}
+	return engine.allNoRoute
+}
+
 func (engine *Engine) rebuild404Handlers() {
 	engine.allNoRoute = engine.combineHandlers(engine.noRoute)
 }
@@ -264,12 +435,89 @@ func (engine *Engine) addRoute(method, path string, handlers HandlersChain) {
 	root.addRoute(path, handlers)
 }
 
+func (engine *Engine) trackChain(handlers HandlersChain, globals int) {
+	if len(handlers) == 0 {
+		// addRoute reports the missing handlers
+		return
+	}
+	if engine.chainGlobals == nil {
+		engine.chainGlobals = make(map[*HandlerFunc]int)
+	}
+	engine.chainGlobals[&handlers[0]] = globals
+}
+
+// Rebuild adds the engine middleware registered with Use after the routes to their handlers chains.
+// The late middleware run right after the engine middleware the route already had, before the
+// middleware of its groups, just like if they had been registered first. The RouterGroup.NoRoute
+// handlers are rebuilt too.
+// It must be called before serving requests, it is not safe to call concurrently with them.
+func (engine *Engine) Rebuild() {
+	rebuilt := make(map[*HandlerFunc]HandlersChain)
+	for _, tree := range engine.trees {
+		tree.root.walk(func(n *node) {
+			n.handlers = engine.rebuildChain(n.handlers, rebuilt)
+		})
+	}
+	for i := range engine.groupNoRoutes {
+		engine.groupNoRoutes[i].handlers = engine.rebuildChain(engine.groupNoRoutes[i].handlers, rebuilt)
+	}
+}
+
+// rebuildChain returns chain with the late engine middleware, the chains shared by
+// several routes are rebuilt once.
+func (engine *Engine) rebuildChain(chain HandlersChain, rebuilt map[*HandlerFunc]HandlersChain) HandlersChain {
+	if len(chain) == 0 {
+		return chain
+	}
+	old := &chain[0]
+	if handlers, ok := rebuilt[old]; ok {
+		return handlers
+	}
+	globals, ok := engine.chainGlobals[old]
+	if !ok || globals >= len(engine.Handlers) {
+		return chain
+	}
+	late := engine.Handlers[globals:]
+	handlers := make(HandlersChain, 0, len(chain)+len(late))
+	handlers = append(handlers, chain[:globals]...)
+	handlers = append(handlers, late...)
+	handlers = append(handlers, chain[globals:]...)
+	assert1(len(handlers) < int(abortIndex), "too many handlers")
+
+	delete(engine.chainGlobals, old)
+	engine.trackChain(handlers, len(engine.Handlers))
+	rebuilt[old] = handlers
+	return handlers
+}
+
+func (engine *Engine) setRouteMeta(route routeKey, key string, value interface{}) {
+	if engine.routesMeta == nil {
+		engine.routesMeta = make(map[routeKey]H)
+	}
+
+	// an optional param registers the route with and without it
+	if base, ok := optionalParamBase(route.path); ok {
+		engine.setRouteMeta(routeKey{method: route.method, path: base}, key, value)
+		route.path = route.path[:len(route.path)-1]
+	}
+
+	meta := engine.routesMeta[route]
+	if meta == nil {
+		meta = H{}
+		engine.routesMeta[route] = meta
+	}
+	meta[key] = value
+}
+
 // Routes returns a slice of registered routes, including some useful information, such as:
-// the http method, path and the handler name.
+// the http method, path, the handler name and the metadata set with WithMeta.
 func (engine *Engine) Routes() (routes RoutesInfo) {
 	for _, tree := range engine.trees {
 		routes = iterate("", tree.method, routes, tree.root)
 	}
+	for i := range routes {
+		routes[i].Meta = engine.routesMeta[routeKey{method: routes[i].Method, path: routes[i].Path}]
+	}
 	return routes
 }
 
@@ -357,18 +605,74 @@ func (engine *Engine) RunListener(listener net.Listener) (err error) {
 	return
 }
 
+// RunWithConnState attaches the router to a http.Server and starts listening and serving HTTP requests,
+// hook is called each time a client connection changes state, see http.Server.ConnState.
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) RunWithConnState(addr string, hook func(net.Conn, http.ConnState)) (err error) {
+	debugPrint("Listening and serving HTTP on %s\n", addr)
+	defer func() { debugPrintError(err) }()
+
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   engine,
+		ConnState: hook,
+	}
+	err = server.ListenAndServe()
+	return
+}
+
 // ServeHTTP conforms to the http.Handler interface.
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	c := engine.pool.Get().(*Context)
 	c.writermem.reset(w)
+	if engine.HandlerTimeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), engine.HandlerTimeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
 	c.Request = req
 	c.reset()
+	c.cleanups = nil
+	c.deadline = engine.HandlerTimeout > 0
 
-	engine.handleHTTPRequest(c)
+	if engine.DrainBodyOnAbort {
+		c.OnResponse(engine.closeUndrainable)
+	}
+
+	engine.handleWithCleanups(c)
+	if engine.DrainBodyOnAbort && c.IsAborted() && c.Request.Body != nil &&
+		c.Request.ContentLength <= engine.MaxDrainBytes {
+		io.CopyN(ioutil.Discard, c.Request.Body, engine.MaxDrainBytes) // nolint: errcheck
+	}
 
 	engine.pool.Put(c)
 }
 
+// closeUndrainable asks the client to close the connection when the chain is aborted
+// and the body is too long to be drained, see MaxDrainBytes.
+func (engine *Engine) closeUndrainable(c *Context) {
+	if c.IsAborted() && c.Request.ContentLength > engine.MaxDrainBytes {
+		c.Header("Connection", "close")
+	}
+}
+
+// WaitShadowRequests blocks until the requests mirrored by Context.Shadow are handled,
+// e.g. after http.Server.Shutdown returns, as the server does not track them.
+func (engine *Engine) WaitShadowRequests() {
+	engine.shadows.Wait()
+}
+
+// handleWithCleanups handles the request then runs the OnCleanup functions,
+// even when a handler panics.
+func (engine *Engine) handleWithCleanups(c *Context) {
+	defer c.runCleanups()
+	if engine.MaxConcurrentMultipart > 0 {
+		engine.handleMultipartGated(c)
+	} else {
+		engine.handleHTTPRequest(c)
+	}
+}
+
 // HandleContext re-enter a context that has been rewritten.
 // This can be done by setting c.Request.URL.Path to your new target.
 // Disclaimer: You can loop yourself to death with this, use wisely.
@@ -406,12 +710,16 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 			c.handlers = value.handlers
 			c.Params = value.params
 			c.fullPath = value.fullPath
-			c.Next()
+			if engine.ValidateResponseSchemas && IsDebugging() {
+				engine.nextValidatingResponse(c)
+			} else {
+				c.Next()
+			}
 			c.writermem.WriteHeaderNow()
 			return
 		}
 		if httpMethod != "CONNECT" && rPath != "/" {
-			if value.tsr && engine.RedirectTrailingSlash {
+			if value.tsr && engine.trailingSlashRedirect(root, httpMethod, rPath, unescape) {
 				redirectTrailingSlash(c)
 				return
 			}
@@ -423,23 +731,44 @@ func (engine *Engine) handleHTTPRequest(c *Context) {
 	}
 
 	if engine.HandleMethodNotAllowed {
+		var allowed []string
 		for _, tree := range engine.trees {
 			if tree.method == httpMethod {
 				continue
 			}
 			if value := tree.root.getValue(rPath, nil, unescape); value.handlers != nil {
-				c.handlers = engine.allNoMethod
-				serveError(c, http.StatusMethodNotAllowed, default405Body)
-				return
+				allowed = append(allowed, tree.method)
 			}
 		}
+		if len(allowed) > 0 {
+			c.handlers = engine.allNoMethod
+			c.Writer.Header().Set("Allow", strings.Join(allowed, ", "))
+			serveError(c, http.StatusMethodNotAllowed, default405Body)
+			return
+		}
 	}
-	c.handlers = engine.allNoRoute
+	c.handlers = engine.noRouteHandlers(rPath)
 	serveError(c, http.StatusNotFound, default404Body)
 }
 
 var mimePlain = []string{MIMEPlain}
 
+// handleMultipartGated handles the request unless it is multipart and
+// MaxConcurrentMultipart multipart requests are already being handled.
+func (engine *Engine) handleMultipartGated(c *Context) {
+	if !strings.HasPrefix(strings.ToLower(c.ContentType()), "multipart/") {
+		engine.handleHTTPRequest(c)
+		return
+	}
+	defer atomic.AddInt32(&engine.multipartActive, -1)
+	if atomic.AddInt32(&engine.multipartActive, 1) > int32(engine.MaxConcurrentMultipart) {
+		c.handlers = engine.Handlers
+		serveError(c, http.StatusServiceUnavailable, default503Body)
+		return
+	}
+	engine.handleHTTPRequest(c)
+}
+
 func serveError(c *Context, code int, defaultMessage []byte) {
 	c.writermem.status = code
 	c.Next()
@@ -457,6 +786,23 @@ func serveError(c *Context, code int, defaultMessage []byte) {
 	c.writermem.WriteHeaderNow()
 }
 
+// trailingSlashRedirect reports whether rPath is redirected to the route registered with
+// or without its trailing slash, following the RouterGroup override of that route if any.
+func (engine *Engine) trailingSlashRedirect(root *node, httpMethod, rPath string, unescape bool) bool {
+	if len(engine.trailingSlash) > 0 {
+		other := rPath + "/"
+		if rPath[len(rPath)-1] == '/' {
+			other = rPath[:len(rPath)-1]
+		}
+		if value := root.getValue(other, nil, unescape); value.handlers != nil {
+			if enabled, ok := engine.trailingSlash[routeKey{method: httpMethod, path: value.fullPath}]; ok {
+				return enabled
+			}
+		}
+	}
+	return engine.RedirectTrailingSlash
+}
+
 func redirectTrailingSlash(c *Context) {
 	req := c.Request
 	p := req.URL.Path
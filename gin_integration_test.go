@@ -144,6 +144,32 @@ func TestRunWithPort(t *testing.T) {
 	testRequest(t, "http://localhost:5150/example")
 }
 
+func TestRunWithConnState(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		states = map[http.ConnState]int{}
+	)
+	router := New()
+	go func() {
+		router.GET("/example", func(c *Context) { c.String(http.StatusOK, "it worked") })
+		assert.Equal(t, nil, router.RunWithConnState(":5151", func(conn net.Conn, state http.ConnState) {
+			mu.Lock()
+			states[state]++
+			mu.Unlock()
+		}))
+	}()
+	// have to wait for the goroutine to start and run the server
+	// otherwise the main thread will complete
+	time.Sleep(5 * time.Millisecond)
+
+	testRequest(t, "http://localhost:5151/example")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, states[http.StateNew])
+	assert.Equal(t, 1, states[http.StateActive])
+}
+
 func TestUnixSocket(t *testing.T) {
 	router := New()
 
@@ -13,6 +13,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -397,6 +398,60 @@ func TestRebuild404Handlers(t *testing.T) {
 
 }
 
+func TestEngineRebuild(t *testing.T) {
+	var trace string
+	router := New()
+	router.Use(func(c *Context) { trace += "A" })
+	router.GET("/root", func(c *Context) { trace += "r" })
+	api := router.Group("/api", func(c *Context) { trace += "G" })
+	api.GET("/users", func(c *Context) { trace += "u" })
+	api.Any("/any", func(c *Context) { trace += "a" })
+	api.HandleClean("GET", "/health", func(c *Context) { trace += "h" })
+	api.NoRoute(func(c *Context) { trace += "n" })
+
+	router.Use(func(c *Context) { trace += "B" })
+	router.GET("/late", func(c *Context) { trace += "l" })
+
+	// late middleware only apply to the routes registered after them
+	performRequest(router, "GET", "/api/users")
+	assert.Equal(t, "AGu", trace)
+
+	router.Rebuild()
+	tests := []struct {
+		method string
+		path   string
+		trace  string
+	}{
+		{"GET", "/root", "ABr"},
+		{"GET", "/api/users", "ABGu"},
+		{"GET", "/api/any", "ABGa"},
+		{"POST", "/api/any", "ABGa"},
+		{"GET", "/api/health", "ABh"},
+		{"GET", "/late", "ABl"},
+	}
+	for _, tt := range tests {
+		trace = ""
+		w := performRequest(router, tt.method, tt.path)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, tt.trace, trace)
+	}
+
+	trace = ""
+	performRequest(router, "GET", "/api/missing")
+	assert.Equal(t, "ABGn", trace)
+
+	// rebuilding twice doesn't add the middleware again
+	router.Rebuild()
+	trace = ""
+	performRequest(router, "GET", "/api/users")
+	assert.Equal(t, "ABGu", trace)
+}
+
+func TestEngineRouteWithoutHandlers(t *testing.T) {
+	assert.PanicMatches(t, func() { New().GET("/x") }, "there must be at least one handler")
+	assert.PanicMatches(t, func() { New().Group("/api").HandleClean("GET", "/x") }, "there must be at least one handler")
+}
+
 func TestNoMethodWithGlobalHandlers(t *testing.T) {
 	var middleware0 HandlerFunc = func(c *Context) {}
 	var middleware1 HandlerFunc = func(c *Context) {}
@@ -478,6 +533,124 @@ func TestListOfRoutes(t *testing.T) {
 	})
 }
 
+func TestListOfRoutesNestedGroups(t *testing.T) {
+	router := New()
+	v1 := router.Group("/api").Group("/v1")
+	{
+		v1.PUT("/users/:id", handlerTest1)
+		v1.DELETE("/users/:id", handlerTest2)
+		v1.GET("/users/:id/files/*filepath", handlerTest1)
+	}
+
+	list := router.Routes()
+
+	assert.Equal(t, 3, len(list))
+	assertRoutePresent(t, list, RouteInfo{
+		Method:  "PUT",
+		Path:    "/api/v1/users/:id",
+		Handler: "^(.*/vendor/)?github.com/manucorporat/gin-diet.handlerTest1$",
+	})
+	assertRoutePresent(t, list, RouteInfo{
+		Method:  "DELETE",
+		Path:    "/api/v1/users/:id",
+		Handler: "^(.*/vendor/)?github.com/manucorporat/gin-diet.handlerTest2$",
+	})
+	assertRoutePresent(t, list, RouteInfo{
+		Method:  "GET",
+		Path:    "/api/v1/users/:id/files/*filepath",
+		Handler: "^(.*/vendor/)?github.com/manucorporat/gin-diet.handlerTest1$",
+	})
+	for _, route := range list {
+		assert.NotEqual(t, nil, route.HandlerFunc)
+	}
+}
+
+func TestEngineDrainBodyOnAbort(t *testing.T) {
+	router := New()
+	router.POST("/upload", func(c *Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+	router.POST("/ok", func(c *Context) {})
+
+	body := strings.NewReader(strings.Repeat("x", 1<<20))
+	req, _ := http.NewRequest("POST", "/upload", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, 1<<20, body.Len())
+
+	router.DrainBodyOnAbort = true
+	router.MaxDrainBytes = 1 << 20
+	req, _ = http.NewRequest("POST", "/upload", body)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 0, body.Len())
+	assert.Equal(t, "", w.Result().Header.Get("Connection"))
+
+	// past the limit, the body is left to net/http
+	router.MaxDrainBytes = 1 << 10
+	body.Reset(strings.Repeat("x", 1<<20))
+	req, _ = http.NewRequest("POST", "/upload", body)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 1<<20, body.Len())
+	assert.Equal(t, "close", w.Result().Header.Get("Connection"))
+
+	// aborted before writing a body
+	router.POST("/reject", func(c *Context) {
+		c.Abort()
+		c.String(http.StatusForbidden, "rejected")
+	})
+	req, _ = http.NewRequest("POST", "/reject", body)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "rejected", w.Body.String())
+	assert.Equal(t, "close", w.Result().Header.Get("Connection"))
+
+	// not aborted
+	body.Reset("unread")
+	req, _ = http.NewRequest("POST", "/ok", body)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, 6, body.Len())
+}
+
+func TestEngineMaxConcurrentMultipart(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	router := New()
+	router.MaxConcurrentMultipart = 2
+	router.POST("/upload", func(c *Context) {
+		if c.Query("wait") != "" {
+			entered <- struct{}{}
+			<-release
+		}
+	})
+	multipart := header{Key: "Content-Type", Value: MIMEMultipartPOSTForm + "; boundary=foo"}
+
+	done := make(chan int)
+	for i := 0; i < 2; i++ {
+		go func() {
+			done <- performRequest(router, "POST", "/upload?wait=1", multipart).Code
+		}()
+		<-entered
+	}
+
+	w := performRequest(router, "POST", "/upload", multipart)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "503 service unavailable", w.Body.String())
+	// the other requests are not limited
+	w = performRequest(router, "POST", "/upload")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, http.StatusOK, <-done)
+
+	w = performRequest(router, "POST", "/upload", multipart)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&router.multipartActive))
+}
+
 func TestEngineHandleContext(t *testing.T) {
 	r := New()
 	r.GET("/", func(c *Context) {
@@ -544,3 +717,14 @@ func assertRoutePresent(t *testing.T, gotRoutes RoutesInfo, wantRoute RouteInfo)
 
 func handlerTest1(c *Context) {}
 func handlerTest2(c *Context) {}
+
+func TestAddRouteParamNameConflict(t *testing.T) {
+	router := New()
+	router.addRoute("GET", "/users/:id", HandlersChain{func(_ *Context) {}})
+	router.addRoute("GET", "/users/:id/posts", HandlersChain{func(_ *Context) {}})
+	router.addRoute("POST", "/users/:name", HandlersChain{func(_ *Context) {}})
+
+	assert.PanicMatches(t, func() {
+		router.addRoute("GET", "/users/:name", HandlersChain{func(_ *Context) {}})
+	}, "param ':name' in new path '/users/:name' conflicts with existing param ':id' in existing prefix '/users/:id', params at the same position must have the same name")
+}
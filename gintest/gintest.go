@@ -0,0 +1,54 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package gintest provides helpers for testing gin handlers.
+package gintest
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+)
+
+// TestingT is the subset of *testing.T used by the helpers.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertJSON reports an error if the body of the recorder is not the JSON encoding of expected,
+// regardless of the key order. expected can also be a string or []byte holding a JSON document,
+// e.g. gintest.AssertJSON(t, w, `{"status":"ok"}`). It returns whether the body matched.
+func AssertJSON(t TestingT, w *httptest.ResponseRecorder, expected interface{}) bool {
+	t.Helper()
+
+	var want []byte
+	switch e := expected.(type) {
+	case string:
+		want = []byte(e)
+	case []byte:
+		want = e
+	default:
+		var err error
+		if want, err = json.Marshal(expected); err != nil {
+			t.Errorf("can not encode the expected value: %v", err)
+			return false
+		}
+	}
+
+	var got, wanted interface{}
+	if err := json.Unmarshal(want, &wanted); err != nil {
+		t.Errorf("expected value is not valid JSON: %v", err)
+		return false
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Errorf("body is not valid JSON: %v\n\tbody: %s", err, w.Body.String())
+		return false
+	}
+	if !reflect.DeepEqual(got, wanted) {
+		t.Errorf("body does not match the expected JSON\n\tbody:     %s\n\texpected: %s", w.Body.String(), want)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gintest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/assert"
+	"github.com/manucorporat/gin-diet"
+)
+
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func performJSON(obj interface{}) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) { c.JSON(http.StatusOK, obj) })
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAssertJSON(t *testing.T) {
+	w := performJSON(gin.H{"foo": "bar", "list": []int{1, 2}, "nested": gin.H{"a": 1, "b": true}})
+
+	assert.Equal(t, true, AssertJSON(t, w, gin.H{"nested": gin.H{"b": true, "a": 1}, "list": []int{1, 2}, "foo": "bar"}))
+	assert.Equal(t, true, AssertJSON(t, w, `{"list":[1,2],"foo":"bar","nested":{"b":true,"a":1}}`))
+	assert.Equal(t, true, AssertJSON(t, w, []byte(`{"foo":"bar","list":[1,2],"nested":{"a":1,"b":true}}`)))
+
+	type user struct {
+		Name string `json:"name"`
+	}
+	assert.Equal(t, true, AssertJSON(t, performJSON(user{"manu"}), gin.H{"name": "manu"}))
+}
+
+func TestAssertJSONMismatch(t *testing.T) {
+	w := performJSON(gin.H{"foo": "bar", "list": []int{1, 2}})
+
+	rt := &recordingT{}
+	assert.Equal(t, false, AssertJSON(rt, w, gin.H{"foo": "bar", "list": []int{2, 1}}))
+	assert.Equal(t, false, AssertJSON(rt, w, gin.H{"foo": "bar"}))
+	assert.Equal(t, false, AssertJSON(rt, w, `{"foo":`))
+	assert.Equal(t, false, AssertJSON(rt, httptest.NewRecorder(), gin.H{}))
+	assert.Equal(t, 4, len(rt.errors))
+	assert.Equal(t, "body does not match the expected JSON\n\tbody:     {\"foo\":\"bar\",\"list\":[1,2]}\n\texpected: {\"foo\":\"bar\"}", rt.errors[1])
+}
@@ -344,6 +344,39 @@ func TestBindUriError(t *testing.T) {
 	assert.Equal(t, 200, w1.Code)
 }
 
+func TestShouldBindUriTypes(t *testing.T) {
+	router := New()
+
+	type Book struct {
+		ID     int    `uri:"id"`
+		BookID string `uri:"bookId"`
+		Shelf  int8   `uri:"shelf"`
+		Page   int    `uri:"page"`
+	}
+	router.GET("/users/:id/books/:bookId", func(c *Context) {
+		var book Book
+		assert.Equal(t, nil, c.ShouldBindUri(&book))
+		assert.Equal(t, 42, book.ID)
+		assert.Equal(t, "abc", book.BookID)
+		// no matching param, left untouched
+		assert.Equal(t, 0, book.Page)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/shelves/:shelf", func(c *Context) {
+		var book Book
+		err := c.ShouldBindUri(&book)
+		assert.NotEqual(t, nil, err)
+		c.Status(http.StatusBadRequest)
+	})
+
+	w := performRequest(router, http.MethodGet, "/users/42/books/abc")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 300 overflows int8
+	w = performRequest(router, http.MethodGet, "/shelves/300")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestRaceContextCopy(t *testing.T) {
 	DefaultWriter = os.Stdout
 	router := Default()
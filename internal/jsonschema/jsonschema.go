@@ -0,0 +1,301 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package jsonschema implements the subset of JSON Schema used to validate
+// request and response bodies: type, enum, const, required, properties,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum, maximum, exclusiveMinimum and exclusiveMaximum.
+// Unknown keywords are ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON schema.
+type Schema struct {
+	types                []string
+	enum                 []interface{}
+	constant             interface{}
+	hasConst             bool
+	required             []string
+	properties           map[string]*Schema
+	additionalProperties *Schema
+	noAdditional         bool
+	items                *Schema
+	minItems, maxItems   *int
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+	minimum, maximum     *float64
+	exclusiveMinimum     *float64
+	exclusiveMaximum     *float64
+}
+
+type rawSchema struct {
+	Type                 interface{}                `json:"type"`
+	Enum                 []interface{}              `json:"enum"`
+	Const                json.RawMessage            `json:"const"`
+	Required             []string                   `json:"required"`
+	Properties           map[string]json.RawMessage `json:"properties"`
+	AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	Items                json.RawMessage            `json:"items"`
+	MinItems             *int                       `json:"minItems"`
+	MaxItems             *int                       `json:"maxItems"`
+	MinLength            *int                       `json:"minLength"`
+	MaxLength            *int                       `json:"maxLength"`
+	Pattern              string                     `json:"pattern"`
+	Minimum              *float64                   `json:"minimum"`
+	Maximum              *float64                   `json:"maximum"`
+	ExclusiveMinimum     *float64                   `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64                   `json:"exclusiveMaximum"`
+}
+
+// Compile parses a JSON schema document.
+func Compile(data []byte) (*Schema, error) {
+	var raw rawSchema
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("jsonschema: %v", err)
+	}
+
+	s := &Schema{
+		enum:      raw.Enum,
+		required:  raw.Required,
+		minItems:  raw.MinItems,
+		maxItems:  raw.MaxItems,
+		minLength: raw.MinLength,
+		maxLength: raw.MaxLength,
+		minimum:   raw.Minimum,
+		maximum:   raw.Maximum,
+
+		exclusiveMinimum: raw.ExclusiveMinimum,
+		exclusiveMaximum: raw.ExclusiveMaximum,
+	}
+
+	switch t := raw.Type.(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("jsonschema: invalid type %v", v)
+			}
+			s.types = append(s.types, name)
+		}
+	default:
+		return nil, fmt.Errorf("jsonschema: invalid type %v", t)
+	}
+
+	if len(raw.Const) > 0 {
+		if err := json.Unmarshal(raw.Const, &s.constant); err != nil {
+			return nil, fmt.Errorf("jsonschema: %v", err)
+		}
+		s.hasConst = true
+	}
+
+	if raw.Pattern != "" {
+		re, err := regexp.Compile(raw.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: %v", err)
+		}
+		s.pattern = re
+	}
+
+	if len(raw.Properties) > 0 {
+		s.properties = make(map[string]*Schema, len(raw.Properties))
+		for name, data := range raw.Properties {
+			prop, err := Compile(data)
+			if err != nil {
+				return nil, err
+			}
+			s.properties[name] = prop
+		}
+	}
+
+	switch data := bytes.TrimSpace(raw.AdditionalProperties); {
+	case len(data) == 0, bytes.Equal(data, []byte("true")):
+	case bytes.Equal(data, []byte("false")):
+		s.noAdditional = true
+	default:
+		additional, err := Compile(data)
+		if err != nil {
+			return nil, err
+		}
+		s.additionalProperties = additional
+	}
+
+	if len(raw.Items) > 0 {
+		items, err := Compile(raw.Items)
+		if err != nil {
+			return nil, err
+		}
+		s.items = items
+	}
+	return s, nil
+}
+
+// MustCompile is like Compile but panics if the schema is invalid.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// ValidateBytes decodes a JSON document and validates it against the schema.
+func (s *Schema) ValidateBytes(data []byte) []string {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return []string{err.Error()}
+	}
+	return s.Validate(v)
+}
+
+// Validate validates a decoded JSON value against the schema, it returns
+// one message for each violation prefixed by the JSON pointer of the value.
+func (s *Schema) Validate(v interface{}) []string {
+	var errs []string
+	s.validate("", v, &errs)
+	return errs
+}
+
+func (s *Schema) validate(ptr string, v interface{}, errs *[]string) {
+	report := func(format string, args ...interface{}) {
+		loc := ptr
+		if loc == "" {
+			loc = "/"
+		}
+		*errs = append(*errs, loc+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(s.types) > 0 && !s.matchType(v) {
+		report("expected %s, got %s", strings.Join(s.types, " or "), typeOf(v))
+		return
+	}
+	if s.hasConst && !reflect.DeepEqual(v, s.constant) {
+		report("must be %v", s.constant)
+	}
+	if len(s.enum) > 0 && !inEnum(s.enum, v) {
+		report("must be one of %v", s.enum)
+	}
+
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			report("length must be >= %d", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			report("length must be <= %d", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("must match %q", s.pattern.String())
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			report("must be >= %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			report("must be <= %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+			report("must be > %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+			report("must be < %v", *s.exclusiveMaximum)
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			report("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			report("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(fmt.Sprintf("%s/%d", ptr, i), item, errs)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				report("missing required property %q", name)
+			}
+		}
+		// sorted, so the messages come in a stable order
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.properties[name]
+			switch {
+			case ok:
+				prop.validate(ptr+"/"+escape(name), v[name], errs)
+			case s.additionalProperties != nil:
+				s.additionalProperties.validate(ptr+"/"+escape(name), v[name], errs)
+			case s.noAdditional:
+				report("unexpected property %q", name)
+			}
+		}
+	}
+}
+
+func (s *Schema) matchType(v interface{}) bool {
+	for _, t := range s.types {
+		switch typ := typeOf(v); {
+		case t == typ:
+			return true
+		case t == "number" && typ == "integer":
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// escape encodes a property name as a JSON pointer token.
+func escape(name string) string {
+	return strings.Replace(strings.Replace(name, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schema := MustCompile([]byte(`{
+		"type": "object",
+		"required": ["id", "tags"],
+		"additionalProperties": false,
+		"properties": {
+			"id": {"type": "integer", "exclusiveMinimum": 0},
+			"name": {"type": ["string", "null"], "maxLength": 3, "pattern": "^[a-z]+$"},
+			"kind": {"enum": ["a", "b"]},
+			"version": {"const": 1},
+			"tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string"}},
+			"a/b": {"type": "number", "maximum": 1}
+		}
+	}`))
+
+	tests := []struct {
+		doc  string
+		errs []string
+	}{
+		{`{"id": 1, "tags": ["x"]}`, nil},
+		{`{"id": 1, "tags": ["x"], "name": null, "kind": "b", "version": 1, "a/b": 0.5}`, nil},
+		{`[]`, []string{"/: expected object, got array"}},
+		{`{"tags": []}`, []string{
+			`/: missing required property "id"`,
+			"/tags: must have at least 1 items",
+		}},
+		{`{"id": 0, "tags": ["x", 1, "z"], "extra": true}`, []string{
+			`/: unexpected property "extra"`,
+			"/id: must be > 0",
+			"/tags: must have at most 2 items",
+			"/tags/1: expected string, got integer",
+		}},
+		{`{"id": 1, "tags": ["x"], "name": "Long", "kind": "c", "version": 2, "a/b": 2}`, []string{
+			"/a~1b: must be <= 1",
+			"/kind: must be one of [a b]",
+			"/name: length must be <= 3",
+			`/name: must match "^[a-z]+$"`,
+			"/version: must be 1",
+		}},
+		{`{`, []string{"unexpected end of JSON input"}},
+	}
+	for _, tt := range tests {
+		if errs := schema.ValidateBytes([]byte(tt.doc)); !reflect.DeepEqual(errs, tt.errs) {
+			t.Errorf("%s: got %q, want %q", tt.doc, errs, tt.errs)
+		}
+	}
+}
+
+func TestAdditionalPropertiesSchema(t *testing.T) {
+	schema := MustCompile([]byte(`{"additionalProperties": {"type": "boolean"}}`))
+	if errs := schema.ValidateBytes([]byte(`{"a": true, "b": "no"}`)); !reflect.DeepEqual(errs, []string{"/b: expected boolean, got string"}) {
+		t.Errorf("got %q", errs)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	for _, doc := range []string{`[`, `{"type": 1}`, `{"type": [1]}`, `{"pattern": "("}`, `{"items": {"type": 1}}`} {
+		if _, err := Compile([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error", doc)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/manucorporat/gin-diet/render"
+)
+
+// CamelCaseKey turns a Go field name into camelCase, e.g. "UserID" into "userID"
+// and "HTTPServer" into "httpServer". It can be used as Engine.JSONKeyTransform.
+func CamelCaseKey(key string) string {
+	runes := []rune(key)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		// keep the last upper letter of an acronym followed by a word
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// SnakeCaseKey turns a Go field name into snake_case, e.g. "UserID" into "user_id"
+// and "HTTPServer" into "http_server". It can be used as Engine.JSONKeyTransform.
+func SnakeCaseKey(key string) string {
+	var buf strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' && (!unicode.IsUpper(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				buf.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// jsonKeys returns obj with its JSON object keys renamed by the engine JSONKeyTransform,
+// or obj itself when there is no transform or obj can not be encoded, so the
+// renderer reports the error.
+func (c *Context) jsonKeys(obj interface{}) interface{} {
+	if c.engine.JSONKeyTransform == nil {
+		return obj
+	}
+	data, err := render.JSONMarshal(obj)
+	if err != nil {
+		return obj
+	}
+	data, err = transformJSONKeys(data, c.engine.JSONKeyTransform)
+	if err != nil {
+		return obj
+	}
+	return json.RawMessage(data)
+}
+
+// jsonFrame is an object or an array being rewritten by transformJSONKeys.
+type jsonFrame struct {
+	object bool
+	key    bool
+	n      int
+}
+
+// transformJSONKeys rewrites a JSON document renaming its object keys with fn,
+// the order of the keys and the values are kept.
+func transformJSONKeys(data []byte, fn func(string) string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var (
+		buf   bytes.Buffer
+		stack []jsonFrame
+	)
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF && len(stack) == 0 {
+			return buf.Bytes(), nil
+		}
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			buf.WriteByte(byte(delim))
+			if len(stack) > 0 && stack[len(stack)-1].object {
+				stack[len(stack)-1].key = true
+			}
+			continue
+		}
+
+		isKey := false
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.n > 0 && (!top.object || top.key) {
+				buf.WriteByte(',')
+			}
+			switch {
+			case top.object && top.key:
+				tok = fn(tok.(string))
+				isKey = true
+				top.key = false
+				top.n++
+			case top.object:
+				// the value of an object is a container, it resets key when closed
+				if _, ok := tok.(json.Delim); !ok {
+					top.key = true
+				}
+			default:
+				top.n++
+			}
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			buf.WriteByte(byte(delim))
+			stack = append(stack, jsonFrame{object: delim == '{', key: true})
+			continue
+		}
+		if err := enc.Encode(tok); err != nil {
+			return nil, err
+		}
+		// Encode ends the value by a newline
+		buf.Truncate(buf.Len() - 1)
+		if isKey {
+			buf.WriteByte(':')
+		}
+	}
+}
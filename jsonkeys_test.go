@@ -0,0 +1,114 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestCamelCaseKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"":           "",
+		"Name":       "name",
+		"UserID":     "userID",
+		"ID":         "id",
+		"HTTPServer": "httpServer",
+		"userName":   "userName",
+		"user_name":  "user_name",
+	} {
+		assert.Equal(t, want, CamelCaseKey(key))
+	}
+}
+
+func TestSnakeCaseKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"":           "",
+		"Name":       "name",
+		"UserID":     "user_id",
+		"ID":         "id",
+		"HTTPServer": "http_server",
+		"userName":   "user_name",
+		"user_name":  "user_name",
+	} {
+		assert.Equal(t, want, SnakeCaseKey(key))
+	}
+}
+
+func TestTransformJSONKeys(t *testing.T) {
+	data, err := transformJSONKeys([]byte(`{"B":1,"A":[{"C":"<x>"},[],{}],"D":{"E":null,"F":1.50}}`), SnakeCaseKey)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"b":1,"a":[{"c":"<x>"},[],{}],"d":{"e":null,"f":1.50}}`, string(data))
+
+	data, err = transformJSONKeys([]byte(`"Str"`), SnakeCaseKey)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `"Str"`, string(data))
+
+	_, err = transformJSONKeys([]byte(`{"A":`), SnakeCaseKey)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestEngineJSONKeyTransform(t *testing.T) {
+	type user struct {
+		UserID    int
+		FirstName string
+		Tags      []string
+		Friends   []user `json:",omitempty"`
+	}
+	router := New()
+	router.JSONKeyTransform = CamelCaseKey
+	router.GET("/json", func(c *Context) {
+		c.JSON(http.StatusOK, user{UserID: 1, FirstName: "<gin>", Friends: []user{{UserID: 2}}})
+	})
+	router.GET("/pure", func(c *Context) {
+		c.PureJSON(http.StatusOK, H{"HTMLBody": "<b>"})
+	})
+	router.GET("/indented", func(c *Context) {
+		c.IndentedJSON(http.StatusOK, H{"FirstName": "gin"})
+	})
+	router.GET("/ascii", func(c *Context) {
+		c.AsciiJSON(http.StatusOK, H{"FirstName": "ĝin"})
+	})
+	router.GET("/jsonp", func(c *Context) {
+		c.JSONP(http.StatusOK, H{"FirstName": "gin"})
+	})
+	router.GET("/content-type", func(c *Context) {
+		c.JSONWithContentType(http.StatusOK, "application/vnd.api+json", H{"FirstName": "gin"})
+	})
+	router.GET("/stream", func(c *Context) {
+		ch := make(chan interface{}, 2)
+		ch <- H{"FirstName": "gin"}
+		ch <- H{"LastName": "diet"}
+		close(ch)
+		c.JSONStream(http.StatusOK, ch)
+	})
+
+	w := performRequest(router, http.MethodGet, "/json")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"userID":1,"firstName":"\u003cgin\u003e","tags":null,"friends":[{"userID":2,"firstName":"","tags":null}]}`, w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = performRequest(router, http.MethodGet, "/pure")
+	assert.Equal(t, "{\"htmlBody\":\"<b>\"}\n", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/indented")
+	assert.Equal(t, "{\n    \"firstName\": \"gin\"\n}", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/ascii")
+	assert.Equal(t, `{"firstName":"\u011din"}`, w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/jsonp?callback=cb")
+	assert.Equal(t, `cb({"firstName":"gin"});`, w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/content-type")
+	assert.Equal(t, `{"firstName":"gin"}`, w.Body.String())
+
+	stream := CreateTestResponseRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/stream", nil)
+	router.ServeHTTP(stream, req)
+	assert.Equal(t, `[{"firstName":"gin"},{"lastName":"diet"}]`, stream.Body.String())
+}
@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/manucorporat/gin-diet/internal/json"
 )
 
 type consoleColorModeValue int
@@ -45,6 +47,11 @@ type LoggerConfig struct {
 	// SkipPaths is a url path array which logs are not written.
 	// Optional.
 	SkipPaths []string
+
+	// SlowThreshold is the latency above which a request is logged as slow,
+	// the default formatter marks such requests with [SLOW].
+	// Optional. Default value is 0, which disables it.
+	SlowThreshold time.Duration
 }
 
 // LogFormatter gives the signature of the formatter function passed to LoggerWithFormatter
@@ -74,6 +81,8 @@ type LogFormatterParams struct {
 	BodySize int
 	// Keys are the keys set on the request's context.
 	Keys map[string]interface{}
+	// Slow is true when Latency exceeds the configured SlowThreshold.
+	Slow bool
 }
 
 // StatusCodeColor is the ANSI color for appropriately logging http status code to a terminal.
@@ -139,7 +148,12 @@ var defaultLogFormatter = func(param LogFormatterParams) string {
 		// Truncate in a golang < 1.8 safe way
 		param.Latency = param.Latency - param.Latency%time.Second
 	}
-	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+	marker := "[GIN]"
+	if param.Slow {
+		marker = "[GIN] [SLOW]"
+	}
+	return fmt.Sprintf("%s %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		marker,
 		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
 		statusColor, param.StatusCode, resetColor,
 		param.Latency,
@@ -150,6 +164,40 @@ var defaultLogFormatter = func(param LogFormatterParams) string {
 	)
 }
 
+// jsonLogEntry is the line written by JSONLogFormatter.
+type jsonLogEntry struct {
+	Time         time.Time     `json:"time"`
+	Status       int           `json:"status"`
+	Latency      time.Duration `json:"latency"`
+	Method       string        `json:"method"`
+	Path         string        `json:"path"`
+	ClientIP     string        `json:"clientIP"`
+	UserAgent    string        `json:"userAgent"`
+	ErrorMessage string        `json:"errorMessage,omitempty"`
+}
+
+// JSONLogFormatter is a log format function writing one JSON object per line, for the log
+// aggregators. The latency is in nanoseconds. Use it with LoggerWithFormatter or LoggerWithConfig.
+func JSONLogFormatter(param LogFormatterParams) string {
+	entry := jsonLogEntry{
+		Time:         param.TimeStamp,
+		Status:       param.StatusCode,
+		Latency:      param.Latency,
+		Method:       param.Method,
+		Path:         param.Path,
+		ClientIP:     param.ClientIP,
+		ErrorMessage: param.ErrorMessage,
+	}
+	if param.Request != nil {
+		entry.UserAgent = param.Request.UserAgent()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return ""
+	}
+	return string(line) + "\n"
+}
+
 // DisableConsoleColor disables color output in the console.
 func DisableConsoleColor() {
 	consoleColorMode = disableColor
@@ -248,6 +296,7 @@ func LoggerWithConfig(conf LoggerConfig) HandlerFunc {
 			// Stop timer
 			param.TimeStamp = time.Now()
 			param.Latency = param.TimeStamp.Sub(start)
+			param.Slow = conf.SlowThreshold > 0 && param.Latency > conf.SlowThreshold
 
 			param.ClientIP = c.ClientIP()
 			param.Method = c.Request.Method
@@ -6,9 +6,11 @@ package gin
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -230,6 +232,46 @@ func TestLoggerWithConfigFormatting(t *testing.T) {
 
 }
 
+func TestLoggerWithJSONFormatter(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{
+		Output:    buffer,
+		Formatter: JSONLogFormatter,
+	}))
+	router.GET("/example", func(c *Context) {
+		c.Error(errors.New("boom")) // nolint: errcheck
+		c.Status(http.StatusTeapot)
+	})
+	req, _ := http.NewRequest("GET", "/example?a=100", nil)
+	req.Header.Set("User-Agent", "gin-test")
+	req.RemoteAddr = "20.20.20.20:1234"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, true, strings.HasSuffix(buffer.String(), "}\n"))
+	assert.Equal(t, 1, strings.Count(buffer.String(), "\n"))
+
+	var entry struct {
+		Time         time.Time `json:"time"`
+		Status       int       `json:"status"`
+		Latency      int64     `json:"latency"`
+		Method       string    `json:"method"`
+		Path         string    `json:"path"`
+		ClientIP     string    `json:"clientIP"`
+		UserAgent    string    `json:"userAgent"`
+		ErrorMessage string    `json:"errorMessage"`
+	}
+	assert.Equal(t, nil, json.Unmarshal(buffer.Bytes(), &entry))
+	assert.Equal(t, false, entry.Time.IsZero())
+	assert.Equal(t, http.StatusTeapot, entry.Status)
+	assert.Equal(t, true, entry.Latency >= 0)
+	assert.Equal(t, "GET", entry.Method)
+	assert.Equal(t, "/example?a=100", entry.Path)
+	assert.Equal(t, "20.20.20.20", entry.ClientIP)
+	assert.Equal(t, "gin-test", entry.UserAgent)
+	assert.Equal(t, "Error #01: boom\n", entry.ErrorMessage)
+}
+
 func TestDefaultLogFormatter(t *testing.T) {
 	timeStamp := time.Unix(1544173902, 0).UTC()
 
@@ -282,6 +324,27 @@ func TestDefaultLogFormatter(t *testing.T) {
 	assert.Equal(t, "[GIN] 2018/12/07 - 09:11:42 |\x1b[97;42m 200 \x1b[0m|            5s |     20.20.20.20 |\x1b[97;44m GET     \x1b[0m \"/\"\n", defaultLogFormatter(termTrueParam))
 	assert.Equal(t, "[GIN] 2018/12/07 - 09:11:42 |\x1b[97;42m 200 \x1b[0m|    2743h29m3s |     20.20.20.20 |\x1b[97;44m GET     \x1b[0m \"/\"\n", defaultLogFormatter(termTrueLongDurationParam))
 
+	termFalseParam.Slow = true
+	assert.Equal(t, "[GIN] [SLOW] 2018/12/07 - 09:11:42 | 200 |            5s |     20.20.20.20 | GET      \"/\"\n", defaultLogFormatter(termFalseParam))
+}
+
+func TestLoggerWithConfigSlowThreshold(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	router := New()
+	router.Use(LoggerWithConfig(LoggerConfig{Output: buffer, SlowThreshold: 10 * time.Millisecond}))
+	router.GET("/slow", func(c *Context) {
+		time.Sleep(20 * time.Millisecond)
+	})
+	router.GET("/fast", func(c *Context) {})
+
+	performRequest(router, "GET", "/slow")
+	assert.Equal(t, true, strings.HasPrefix(buffer.String(), "[GIN] [SLOW] "))
+	Contains(t, buffer.String(), "/slow")
+
+	buffer.Reset()
+	performRequest(router, "GET", "/fast")
+	assert.Equal(t, false, strings.Contains(buffer.String(), "[SLOW]"))
+	Contains(t, buffer.String(), "/fast")
 }
 
 func TestColorForMethod(t *testing.T) {
@@ -434,6 +497,32 @@ func TestForceConsoleColor(t *testing.T) {
 	consoleColorMode = autoColor
 }
 
+func TestLoggerConsoleColor(t *testing.T) {
+	defer func() {
+		consoleColorMode = autoColor
+	}()
+
+	buffer := new(bytes.Buffer)
+	router := New()
+	router.Use(LoggerWithWriter(buffer))
+	router.GET("/example", func(c *Context) {})
+
+	// a bytes.Buffer is never a terminal, so auto mode has no colors
+	performRequest(router, "GET", "/example")
+	assert.Equal(t, false, strings.Contains(buffer.String(), "\x1b["))
+
+	ForceConsoleColor()
+	buffer.Reset()
+	performRequest(router, "GET", "/example")
+	Contains(t, buffer.String(), "\x1b[97;42m 200 \x1b[0m")
+
+	DisableConsoleColor()
+	buffer.Reset()
+	performRequest(router, "GET", "/example")
+	Contains(t, buffer.String(), "200")
+	assert.Equal(t, false, strings.Contains(buffer.String(), "\x1b["))
+}
+
 func Panics(t *testing.T, cb func()) {
 	defer func() {
 		if r := recover(); r == nil {
@@ -7,6 +7,7 @@ package gin
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"testing"
 
 	"github.com/go-playground/assert"
@@ -198,6 +199,106 @@ func TestMiddlewareAbortHandlersChainAndNext(t *testing.T) {
 	assert.Equal(t, "ACB", signature)
 }
 
+func TestMiddlewareOnCleanup(t *testing.T) {
+	signature := ""
+	router := New()
+	router.Use(func(c *Context) {
+		signature += "A"
+		c.OnCleanup(func() { signature += "1" })
+		c.Next()
+		signature += "B"
+	})
+	router.Use(func(c *Context) {
+		c.OnCleanup(func() { signature += "2" })
+		if c.Query("abort") != "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+	})
+	router.GET("/", func(c *Context) {
+		c.OnCleanup(func() { signature += "3" })
+		signature += "X"
+	})
+
+	// RUN
+	w := performRequest(router, "GET", "/")
+
+	// TEST
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "AXB321", signature)
+
+	signature = ""
+	w = performRequest(router, "GET", "/?abort=1")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "AB21", signature)
+}
+
+func TestMiddlewareOnCleanupHandleContext(t *testing.T) {
+	signature := ""
+	router := New()
+	router.GET("/a", func(c *Context) {
+		c.OnCleanup(func() { signature += "1" })
+		c.Request.URL.Path = "/b"
+		router.HandleContext(c)
+	})
+	router.GET("/b", func(c *Context) {
+		c.OnCleanup(func() { signature += "2" })
+		signature += "B"
+	})
+
+	performRequest(router, "GET", "/a")
+	assert.Equal(t, "B21", signature)
+}
+
+func TestMiddlewareOnCleanupPanic(t *testing.T) {
+	signature := ""
+	router := New()
+	router.GET("/", func(c *Context) {
+		c.OnCleanup(func() { signature += "1" })
+		panic("handler failure")
+	})
+
+	assert.PanicMatches(t, func() { performRequest(router, "GET", "/") }, "handler failure")
+	assert.Equal(t, "1", signature)
+}
+
+func TestMiddlewareOnResponse(t *testing.T) {
+	signature := ""
+	router := New()
+	router.Use(func(c *Context) {
+		c.OnResponse(func(c *Context) {
+			signature += "1"
+			c.Header("X-Status", strconv.Itoa(c.Writer.Status()))
+		})
+		c.Next()
+		signature += "B"
+	})
+	router.Use(func(c *Context) {
+		c.OnResponse(func(c *Context) {
+			signature += "2"
+			if c.Writer.Status() == http.StatusNotFound {
+				c.Status(http.StatusGone)
+			}
+		})
+	})
+	router.GET("/:status", func(c *Context) {
+		code, _ := strconv.Atoi(c.Param("status"))
+		c.String(code, "body")
+		c.String(code, "more")
+	})
+
+	w := performRequest(router, "GET", "/201")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "201", w.Header().Get("X-Status"))
+	assert.Equal(t, "bodymore", w.Body.String())
+	assert.Equal(t, "21B", signature)
+
+	signature = ""
+	w = performRequest(router, "GET", "/404")
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Equal(t, "410", w.Header().Get("X-Status"))
+	assert.Equal(t, "21B", signature)
+}
+
 // TestFailHandlersChain - ensure that Fail interrupt used middleware in fifo order as
 // as well as Abort
 func TestMiddlewareFailHandlersChain(t *testing.T) {
@@ -40,6 +40,17 @@ var DefaultWriter io.Writer = os.Stdout
 // DefaultErrorWriter is the default io.Writer used by Gin to debug errors
 var DefaultErrorWriter io.Writer = os.Stderr
 
+// AddErrorWriter makes DefaultErrorWriter also write to w, e.g. to keep
+// the errors in a file while they still reach os.Stderr.
+// Like DefaultErrorWriter, it must be called before Recovery() is used.
+func AddErrorWriter(w io.Writer) {
+	if DefaultErrorWriter == nil {
+		DefaultErrorWriter = w
+		return
+	}
+	DefaultErrorWriter = io.MultiWriter(DefaultErrorWriter, w)
+}
+
 var ginMode = debugCode
 var modeName = DebugMode
 
@@ -83,6 +94,18 @@ func EnableJsonDecoderDisallowUnknownFields() {
 	binding.EnableDecoderDisallowUnknownFields = true
 }
 
+// SetJsonDecoderMaxDepth sets binding.DecoderMaxDepth to reject the JSON bodies
+// nested deeper than depth, 0 disables the limit.
+func SetJsonDecoderMaxDepth(depth int) {
+	binding.DecoderMaxDepth = depth
+}
+
+// SetJsonDecoderMaxArrayLength sets binding.DecoderMaxArrayLength to reject the JSON
+// bodies holding an array longer than length, 0 disables the limit.
+func SetJsonDecoderMaxArrayLength(length int) {
+	binding.DecoderMaxArrayLength = length
+}
+
 // Mode returns currently gin mode.
 func Mode() string {
 	return modeName
@@ -5,6 +5,8 @@
 package gin
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"testing"
 
@@ -51,3 +53,37 @@ func TestEnableJsonDecoderDisallowUnknownFields(t *testing.T) {
 	EnableJsonDecoderDisallowUnknownFields()
 	assert.Equal(t, true, binding.EnableDecoderDisallowUnknownFields)
 }
+
+func TestSetJsonDecoderMaxDepth(t *testing.T) {
+	assert.Equal(t, 0, binding.DecoderMaxDepth)
+	SetJsonDecoderMaxDepth(32)
+	assert.Equal(t, 32, binding.DecoderMaxDepth)
+	SetJsonDecoderMaxDepth(0)
+}
+
+func TestSetJsonDecoderMaxArrayLength(t *testing.T) {
+	assert.Equal(t, 0, binding.DecoderMaxArrayLength)
+	SetJsonDecoderMaxArrayLength(1000)
+	assert.Equal(t, 1000, binding.DecoderMaxArrayLength)
+	SetJsonDecoderMaxArrayLength(0)
+}
+
+func TestAddErrorWriter(t *testing.T) {
+	defaultErrorWriter := DefaultErrorWriter
+	defer func() {
+		DefaultErrorWriter = defaultErrorWriter
+	}()
+
+	var first, second bytes.Buffer
+	DefaultErrorWriter = &first
+	AddErrorWriter(&second)
+	SetMode(DebugMode)
+	defer SetMode(TestMode)
+	debugPrintError(errors.New("boom"))
+	assert.Equal(t, "[GIN-debug] [ERROR] boom\n", first.String())
+	assert.Equal(t, "[GIN-debug] [ERROR] boom\n", second.String())
+
+	DefaultErrorWriter = nil
+	AddErrorWriter(&first)
+	assert.Equal(t, &first, DefaultErrorWriter)
+}
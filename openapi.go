@@ -0,0 +1,165 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"strings"
+)
+
+// OperationInfoKey is the route metadata key holding the OperationInfo used by OpenAPISpec,
+// e.g. router.GET("/users/:id", getUser).WithMeta(gin.OperationInfoKey, gin.OperationInfo{Summary: "Get a user"}).
+const OperationInfoKey = "_gin-gonic/gin/operationinfokey"
+
+// OperationInfo describes a route in the document generated by OpenAPISpec.
+type OperationInfo struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Params      []ParamInfo
+}
+
+// ParamInfo describes a parameter of an operation.
+// In is one of "path", "query", "header" or "cookie", it defaults to "path" for
+// the params found in the route path and to "query" otherwise.
+type ParamInfo struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+}
+
+var openAPIMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodPut:     true,
+	http.MethodPost:    true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+	http.MethodHead:    true,
+	http.MethodPatch:   true,
+	http.MethodTrace:   true,
+}
+
+// OpenAPISpec assembles a minimal OpenAPI 3 document from the registered routes
+// and the OperationInfo attached to them with WithMeta(OperationInfoKey, ...).
+// The result can be served as is, e.g. router.GET("/openapi.json", func(c *gin.Context) { c.JSON(200, spec) }).
+func (engine *Engine) OpenAPISpec(title, version string) H {
+	paths := H{}
+	for _, route := range engine.Routes() {
+		if !openAPIMethods[route.Method] {
+			continue
+		}
+		path, pathParams := openAPIPath(route.Path)
+		item, ok := paths[path].(H)
+		if !ok {
+			item = H{}
+			paths[path] = item
+		}
+		info, _ := route.Meta[OperationInfoKey].(OperationInfo)
+		item[strings.ToLower(route.Method)] = openAPIOperation(info, pathParams)
+	}
+
+	return H{
+		"openapi": "3.0.3",
+		"info": H{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+	}
+}
+
+func openAPIOperation(info OperationInfo, pathParams []string) H {
+	params := make([]H, 0, len(pathParams)+len(info.Params))
+	index := make(map[string]H, len(pathParams))
+	for _, name := range pathParams {
+		param := H{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   H{"type": "string"},
+		}
+		index[name] = param
+		params = append(params, param)
+	}
+	for _, p := range info.Params {
+		if param, ok := index[p.Name]; ok && (p.In == "" || p.In == "path") {
+			if p.Description != "" {
+				param["description"] = p.Description
+			}
+			continue
+		}
+		in := p.In
+		if in == "" {
+			in = "query"
+		}
+		param := H{
+			"name":   p.Name,
+			"in":     in,
+			"schema": H{"type": "string"},
+		}
+		if p.Required {
+			param["required"] = true
+		}
+		if p.Description != "" {
+			param["description"] = p.Description
+		}
+		params = append(params, param)
+	}
+
+	operation := H{
+		"responses": H{
+			"default": H{"description": "default response"},
+		},
+	}
+	if info.Summary != "" {
+		operation["summary"] = info.Summary
+	}
+	if info.Description != "" {
+		operation["description"] = info.Description
+	}
+	if len(info.Tags) > 0 {
+		operation["tags"] = info.Tags
+	}
+	if len(params) > 0 {
+		operation["parameters"] = params
+	}
+	return operation
+}
+
+// openAPIPath converts a route path to the OpenAPI templated form,
+// e.g. "/users/:id/*file" becomes "/users/{id}/{file}", and returns the param names.
+func openAPIPath(path string) (string, []string) {
+	var (
+		buf    strings.Builder
+		params []string
+	)
+	for i := 0; i < len(path); i++ {
+		if path[i] != ':' && path[i] != '*' {
+			buf.WriteByte(path[i])
+			continue
+		}
+
+		end := strings.IndexByte(path[i:], '/')
+		if end < 0 {
+			end = len(path) - i
+		}
+		end += i
+		// several params in one segment are separated by a non name char
+		if strings.IndexByte(path[i+1:end], ':') >= 0 {
+			j := i + 1
+			for j < end && isParamNameChar(path[j]) {
+				j++
+			}
+			end = j
+		}
+
+		name := path[i+1 : end]
+		params = append(params, name)
+		buf.WriteString("{" + name + "}")
+		i = end - 1
+	}
+	return buf.String(), params
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(c *Context) {}).WithMeta(OperationInfoKey, OperationInfo{
+		Summary: "Get a user",
+		Tags:    []string{"users"},
+		Params: []ParamInfo{
+			{Name: "id", Description: "user id"},
+			{Name: "fields", Description: "fields to return"},
+		},
+	})
+	router.POST("/users", func(c *Context) {}).WithMeta(OperationInfoKey, OperationInfo{Summary: "Create a user"})
+	router.GET("/files/*filepath", func(c *Context) {})
+	router.Any("/any", func(c *Context) {})
+
+	spec := router.OpenAPISpec("test api", "1.0.0")
+	assert.Equal(t, "3.0.3", spec["openapi"])
+	assert.Equal(t, H{"title": "test api", "version": "1.0.0"}, spec["info"])
+
+	paths := spec["paths"].(H)
+	assert.Equal(t, 4, len(paths))
+
+	get := paths["/users/{id}"].(H)["get"].(H)
+	assert.Equal(t, "Get a user", get["summary"])
+	assert.Equal(t, []string{"users"}, get["tags"])
+	assert.Equal(t, []H{
+		{"name": "id", "in": "path", "required": true, "schema": H{"type": "string"}, "description": "user id"},
+		{"name": "fields", "in": "query", "schema": H{"type": "string"}, "description": "fields to return"},
+	}, get["parameters"])
+
+	post := paths["/users"].(H)["post"].(H)
+	assert.Equal(t, "Create a user", post["summary"])
+	assert.Equal(t, nil, post["parameters"])
+
+	file := paths["/files/{filepath}"].(H)["get"].(H)
+	assert.Equal(t, nil, file["summary"])
+	assert.Equal(t, "filepath", file["parameters"].([]H)[0]["name"])
+
+	// CONNECT has no OpenAPI operation
+	assert.Equal(t, 8, len(paths["/any"].(H)))
+
+	_, err := json.Marshal(spec)
+	assert.Equal(t, nil, err)
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		params []string
+	}{
+		{"/", "/", nil},
+		{"/users/:id", "/users/{id}", []string{"id"}},
+		{"/users/:id/posts/:post-id", "/users/{id}/posts/{post-id}", []string{"id", "post-id"}},
+		{"/static/*filepath", "/static/{filepath}", []string{"filepath"}},
+		{"/download/:name.:ext", "/download/{name}.{ext}", []string{"name", "ext"}},
+	}
+	for _, tt := range tests {
+		path, params := openAPIPath(tt.path)
+		assert.Equal(t, tt.want, path)
+		assert.Equal(t, tt.params, params)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package otelgin traces the requests handled by gin with OpenTelemetry.
+package otelgin
+
+import (
+	"net/http"
+
+	"github.com/manucorporat/gin-diet"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware returns a middleware that traces each request with the given OpenTelemetry tracer.
+// The span is named after the matched route (see FullPath), it records the response status
+// and the errors attached to the context, and its context is injected into c.Request.Context(),
+// so the handlers can start child spans from it.
+func Middleware(tracer trace.Tracer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.FullPath()
+		if name == "" {
+			name = "HTTP " + c.Request.Method
+		}
+		ctx, span := tracer.Start(c.Request.Context(), name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", c.Request.Method),
+				attribute.String("http.route", c.FullPath()),
+				attribute.String("http.target", c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		for _, err := range c.Errors {
+			span.RecordError(err.Err)
+		}
+		switch {
+		case status >= http.StatusInternalServerError:
+			span.SetStatus(codes.Error, http.StatusText(status))
+		case len(c.Errors) > 0:
+			span.SetStatus(codes.Error, c.Errors.String())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package otelgin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/assert"
+	"github.com/manucorporat/gin-diet"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func performRequest(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("gin")
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(Middleware(tracer))
+	router.GET("/users/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.String(http.StatusOK, c.Param("id"))
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.AbortWithError(http.StatusInternalServerError, errors.New("boom")) // nolint: errcheck
+	})
+
+	performRequest(router, "GET", "/users/1")
+	performRequest(router, "GET", "/fail")
+	performRequest(router, "GET", "/missing")
+
+	spans := recorder.Ended()
+	assert.Equal(t, 3, len(spans))
+
+	assert.Equal(t, "/users/:id", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, "GET", spanAttribute(spans[0], "http.method").AsString())
+	assert.Equal(t, "/users/1", spanAttribute(spans[0], "http.target").AsString())
+	assert.Equal(t, int64(http.StatusOK), spanAttribute(spans[0], "http.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handlerSpan.SpanID())
+
+	assert.Equal(t, "/fail", spans[1].Name())
+	assert.Equal(t, int64(http.StatusInternalServerError), spanAttribute(spans[1], "http.status_code").AsInt64())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, 1, len(spans[1].Events()))
+
+	assert.Equal(t, "HTTP GET", spans[2].Name())
+	assert.Equal(t, int64(http.StatusNotFound), spanAttribute(spans[2], "http.status_code").AsInt64())
+}
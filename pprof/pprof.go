@@ -0,0 +1,35 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package pprof mounts the net/http/pprof handlers on a gin router.
+//
+// It lives in its own package because importing net/http/pprof registers the
+// handlers on http.DefaultServeMux, which gin itself should not do.
+package pprof
+
+import (
+	"net/http/pprof"
+
+	"github.com/manucorporat/gin-diet"
+)
+
+// DefaultPrefix is the path the pprof handlers are usually served under.
+const DefaultPrefix = "/debug/pprof"
+
+// Register mounts the pprof index and profiles under the given group, the
+// middleware of the group guards them, e.g.
+// pprof.Register(router.Group(pprof.DefaultPrefix, gin.BasicAuth(accounts))).
+// Only GET is routed, except for /symbol which also accepts POST.
+func Register(group *gin.RouterGroup) {
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(pprof.Profile))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(pprof.Trace))
+	// pprof.Index only serves the named profiles under /debug/pprof/
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		group.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package pprof
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert"
+	"github.com/manucorporat/gin-diet"
+)
+
+func performRequest(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRegister(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	Register(router.Group(DefaultPrefix))
+
+	w := performRequest(router, "GET", "/debug/pprof/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Body.String(), "goroutine"))
+
+	w = performRequest(router, "GET", "/debug/pprof/goroutine?debug=1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Body.String(), "goroutine profile"))
+
+	w = performRequest(router, "GET", "/debug/pprof/cmdline")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "DELETE", "/debug/pprof/heap")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRegisterCustomPrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	Register(router.Group("/admin/pprof", gin.BasicAuth(gin.Accounts{"admin": "secret"})))
+
+	w := performRequest(router, "GET", "/admin/pprof/")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req, _ := http.NewRequest("GET", "/admin/pprof/heap?debug=1", nil)
+	req.SetBasicAuth("admin", "secret")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Body.String(), "heap profile"))
+}
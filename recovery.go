@@ -10,10 +10,8 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
-	"net"
 	"net/http"
 	"net/http/httputil"
-	"os"
 	"runtime"
 	"strings"
 	"time"
@@ -42,14 +40,8 @@ func RecoveryWithWriter(out io.Writer) HandlerFunc {
 			if err := recover(); err != nil {
 				// Check for a broken connection, as it is not really a
 				// condition that warrants a panic stack trace.
-				var brokenPipe bool
-				if ne, ok := err.(*net.OpError); ok {
-					if se, ok := ne.Err.(*os.SyscallError); ok {
-						if strings.Contains(strings.ToLower(se.Error()), "broken pipe") || strings.Contains(strings.ToLower(se.Error()), "connection reset by peer") {
-							brokenPipe = true
-						}
-					}
-				}
+				e, isErr := err.(error)
+				brokenPipe := isErr && isBrokenPipe(e)
 				if logger != nil {
 					stack := stack(3)
 					httpRequest, _ := httputil.DumpRequest(c.Request, false)
@@ -73,7 +65,7 @@ func RecoveryWithWriter(out io.Writer) HandlerFunc {
 
 				// If the connection is dead, we can't write a status to it.
 				if brokenPipe {
-					c.Error(err.(error)) // nolint: errcheck
+					c.Error(e) // nolint: errcheck
 					c.Abort()
 				} else {
 					c.AbortWithStatus(http.StatusInternalServerError)
@@ -84,6 +76,25 @@ func RecoveryWithWriter(out io.Writer) HandlerFunc {
 	}
 }
 
+// RecoverRoute returns a middleware that recovers from the panics of the handlers after it
+// and responds with the given status, it localizes the recovery to a route or group:
+// router.GET("/risky", gin.RecoverRoute(http.StatusServiceUnavailable), riskyHandler).
+// The panic is attached to the context as an error. http.ErrAbortHandler is not recovered.
+func RecoverRoute(status int) HandlerFunc {
+	return func(c *Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				c.Error(fmt.Errorf("panic recovered: %v", err)) // nolint: errcheck
+				c.AbortWithStatus(status)
+			}
+		}()
+		c.Next()
+	}
+}
+
 // stack returns a nicely formatted stack frame, skipping skip frames.
 func stack(skip int) []byte {
 	buf := new(bytes.Buffer) // the returned data
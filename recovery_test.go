@@ -90,6 +90,37 @@ func TestPanicWithAbort(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestRecoverRoute(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	var errs []string
+	router := New()
+	router.Use(RecoveryWithWriter(buffer))
+	router.GET("/risky", func(c *Context) {
+		c.Next()
+		errs = c.Errors.Errors()
+	}, RecoverRoute(http.StatusServiceUnavailable), func(c *Context) {
+		panic("risky failure")
+	})
+	router.GET("/other", func(c *Context) {
+		panic("other failure")
+	})
+
+	w := performRequest(router, "GET", "/risky")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, []string{"panic recovered: risky failure"}, errs)
+	assert.Equal(t, "", buffer.String())
+
+	w = performRequest(router, "GET", "/other")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, true, strings.Contains(buffer.String(), "other failure"))
+
+	router = New()
+	router.GET("/abort", RecoverRoute(http.StatusServiceUnavailable), func(c *Context) {
+		panic(http.ErrAbortHandler)
+	})
+	assert.PanicMatches(t, func() { performRequest(router, "GET", "/abort") }, http.ErrAbortHandler.Error())
+}
+
 func TestSource(t *testing.T) {
 	bs := source(nil, 0)
 	assert.Equal(t, []byte("???"), bs)
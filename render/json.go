@@ -49,6 +49,11 @@ type PureJSON struct {
 	Data interface{}
 }
 
+// JSONMarshal encodes the data of the JSON renderers, it defaults to encoding/json
+// and can be replaced once at init by a faster library, e.g. render.JSONMarshal = jsoniter.Marshal.
+// IndentedJSON and PureJSON need encoder options and keep using encoding/json.
+var JSONMarshal = json.Marshal
+
 var jsonContentType = []string{"application/json; charset=utf-8"}
 var jsonpContentType = []string{"application/javascript; charset=utf-8"}
 var jsonAsciiContentType = []string{"application/json"}
@@ -69,7 +74,7 @@ func (r JSON) WriteContentType(w http.ResponseWriter) {
 // WriteJSON marshals the given interface object and writes it with custom ContentType.
 func WriteJSON(w http.ResponseWriter, obj interface{}) error {
 	writeContentType(w, jsonContentType)
-	jsonBytes, err := json.Marshal(obj)
+	jsonBytes, err := JSONMarshal(obj)
 	if err != nil {
 		return err
 	}
@@ -96,7 +101,7 @@ func (r IndentedJSON) WriteContentType(w http.ResponseWriter) {
 // Render (SecureJSON) marshals the given interface object and writes it with custom ContentType.
 func (r SecureJSON) Render(w http.ResponseWriter) error {
 	r.WriteContentType(w)
-	jsonBytes, err := json.Marshal(r.Data)
+	jsonBytes, err := JSONMarshal(r.Data)
 	if err != nil {
 		return err
 	}
@@ -120,7 +125,7 @@ func (r SecureJSON) WriteContentType(w http.ResponseWriter) {
 // Render (JsonpJSON) marshals the given interface object and writes it and its callback with custom ContentType.
 func (r JsonpJSON) Render(w http.ResponseWriter) (err error) {
 	r.WriteContentType(w)
-	ret, err := json.Marshal(r.Data)
+	ret, err := JSONMarshal(r.Data)
 	if err != nil {
 		return err
 	}
@@ -159,7 +164,7 @@ func (r JsonpJSON) WriteContentType(w http.ResponseWriter) {
 // Render (AsciiJSON) marshals the given interface object and writes it with custom ContentType.
 func (r AsciiJSON) Render(w http.ResponseWriter) (err error) {
 	r.WriteContentType(w)
-	ret, err := json.Marshal(r.Data)
+	ret, err := JSONMarshal(r.Data)
 	if err != nil {
 		return err
 	}
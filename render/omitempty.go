@@ -0,0 +1,106 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// OmitEmptyJSON contains the given interface object, it is encoded as JSON
+// without the object members whose value is null, false, 0, "", [] or {},
+// as if every field had the omitempty option. The objects left empty by the
+// omission are omitted too, the array elements are always kept.
+type OmitEmptyJSON struct {
+	Data interface{}
+}
+
+// Render (OmitEmptyJSON) marshals the given interface object and writes it with custom ContentType.
+func (r OmitEmptyJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	jsonBytes, err := JSONMarshal(r.Data)
+	if err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+	dec := json.NewDecoder(bytes.NewReader(jsonBytes))
+	dec.UseNumber()
+	if _, err = omitEmpty(dec, &buf); err != nil {
+		panic(err)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// WriteContentType (OmitEmptyJSON) writes JSON ContentType.
+func (r OmitEmptyJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, jsonContentType)
+}
+
+// omitEmpty copies the next JSON value of dec into buf without its empty object
+// members and reports whether the value itself is empty.
+func omitEmpty(dec *json.Decoder, buf *bytes.Buffer) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		buf.WriteByte(byte(tok))
+		n := 0
+		for dec.More() {
+			mark := buf.Len()
+			if n > 0 {
+				buf.WriteByte(',')
+			}
+			if tok == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return false, err
+				}
+				writeJSONString(buf, key.(string))
+				buf.WriteByte(':')
+			}
+			empty, err := omitEmpty(dec, buf)
+			if err != nil {
+				return false, err
+			}
+			if empty && tok == '{' {
+				buf.Truncate(mark)
+				continue
+			}
+			n++
+		}
+		end, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		buf.WriteByte(byte(end.(json.Delim)))
+		return n == 0, nil
+	case string:
+		writeJSONString(buf, tok)
+		return tok == "", nil
+	case json.Number:
+		buf.WriteString(tok.String())
+		f, err := strconv.ParseFloat(tok.String(), 64)
+		return err == nil && f == 0, nil
+	case bool:
+		buf.WriteString(strconv.FormatBool(tok))
+		return !tok, nil
+	default:
+		buf.WriteString("null")
+		return true, nil
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	// a string never fails to encode
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// ProblemJSON contains the given problem details (RFC 7807).
+type ProblemJSON struct {
+	Data interface{}
+}
+
+// ProblemXML contains the given problem details (RFC 7807).
+type ProblemXML struct {
+	Data interface{}
+}
+
+var problemJSONContentType = []string{"application/problem+json"}
+var problemXMLContentType = []string{"application/problem+xml"}
+
+// Render (ProblemJSON) marshals the given problem details and writes them with custom ContentType.
+func (r ProblemJSON) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	jsonBytes, err := JSONMarshal(r.Data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonBytes)
+	return err
+}
+
+// WriteContentType (ProblemJSON) writes problem+json ContentType.
+func (r ProblemJSON) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, problemJSONContentType)
+}
+
+// Render (ProblemXML) encodes the given problem details and writes them with custom ContentType.
+func (r ProblemXML) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return xml.NewEncoder(w).Encode(r.Data)
+}
+
+// WriteContentType (ProblemXML) writes problem+xml ContentType.
+func (r ProblemXML) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, problemXMLContentType)
+}
@@ -28,6 +28,10 @@ var (
 	_ HTMLRender = HTMLProduction{}
 	_ Render     = Reader{}
 	_ Render     = AsciiJSON{}
+	_ Render     = ProblemJSON{}
+	_ Render     = ProblemXML{}
+	_ Render     = SSEvent{}
+	_ Render     = OmitEmptyJSON{}
 )
 
 func writeContentType(w http.ResponseWriter, value []string) {
@@ -5,6 +5,8 @@
 package render
 
 import (
+	"bytes"
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"html/template"
@@ -37,6 +39,22 @@ func TestRenderJSON(t *testing.T) {
 	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestRenderJSONCustomMarshal(t *testing.T) {
+	JSONMarshal = func(v interface{}) ([]byte, error) {
+		data, err := json.Marshal(v)
+		return bytes.ToUpper(data), err
+	}
+	defer func() {
+		JSONMarshal = json.Marshal
+	}()
+
+	w := httptest.NewRecorder()
+	err := (JSON{map[string]string{"foo": "sentinel"}}).Render(w)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"FOO":"SENTINEL"}`, w.Body.String())
+}
+
 func TestRenderJSONPanics(t *testing.T) {
 	w := httptest.NewRecorder()
 	data := make(chan int)
@@ -45,6 +63,51 @@ func TestRenderJSONPanics(t *testing.T) {
 	Panics(t, func() { assert.Equal(t, nil, (JSON{data}).Render(w)) })
 }
 
+func TestRenderOmitEmptyJSON(t *testing.T) {
+	type inner struct {
+		Zero  int
+		Empty []string
+	}
+	data := struct {
+		Name   string
+		Zero   int
+		Rate   float64
+		Off    bool
+		On     bool
+		Ptr    *int
+		Inner  inner
+		List   []interface{}
+		Labels map[string]string
+		HTML   string
+	}{
+		Name:   "gin",
+		Rate:   0.5,
+		On:     true,
+		List:   []interface{}{0, "", inner{}},
+		Labels: map[string]string{"a": "", "b": "x"},
+		HTML:   "<b>",
+	}
+
+	w := httptest.NewRecorder()
+	err := (OmitEmptyJSON{data}).Render(w)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"Name":"gin","Rate":0.5,"On":true,"List":[0,"",{}],"Labels":{"b":"x"},"HTML":"\u003cb\u003e"}`, w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	err = (OmitEmptyJSON{inner{}}).Render(w)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "{}", w.Body.String())
+}
+
+func TestRenderOmitEmptyJSONPanics(t *testing.T) {
+	w := httptest.NewRecorder()
+	data := make(chan int)
+
+	assert.PanicMatches(t, func() { _ = (OmitEmptyJSON{data}).Render(w) }, "json: unsupported type: chan int")
+}
+
 func TestRenderIndentedJSON(t *testing.T) {
 	w := httptest.NewRecorder()
 	data := map[string]interface{}{
@@ -245,6 +308,27 @@ func TestRenderXML(t *testing.T) {
 	assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestRenderProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	data := map[string]interface{}{"title": "Oops", "status": 500}
+
+	err := (ProblemJSON{data}).Render(w)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"status":500,"title":"Oops"}`, w.Body.String())
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	err = (ProblemXML{xmlmap{"title": "Oops"}}).Render(w)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "<map><title>Oops</title></map>", w.Body.String())
+	assert.Equal(t, "application/problem+xml", w.Header().Get("Content-Type"))
+
+	err = (ProblemJSON{make(chan int)}).Render(httptest.NewRecorder())
+	assert.NotEqual(t, nil, err)
+}
+
 func TestRenderRedirect(t *testing.T) {
 	req, err := http.NewRequest("GET", "/test-redirect", nil)
 	assert.Equal(t, nil, err)
@@ -318,6 +402,36 @@ func TestRenderString(t *testing.T) {
 	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
+func TestRenderSSEvent(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := (SSEvent{Event: "ping", Data: "hello\nworld"}).Render(w)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "event: ping\ndata: hello\ndata: world\n\n", w.Body.String())
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, true, w.Flushed)
+
+	w = httptest.NewRecorder()
+	err = (SSEvent{
+		ID:    "1\n2",
+		Event: "user",
+		Retry: 1000,
+		Data:  map[string]string{"name": "line\nbreak"},
+	}).Render(w)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "id: 12\nevent: user\nretry: 1000\ndata: {\"name\":\"line\\nbreak\"}\n\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	err = (SSEvent{Data: []byte("a\r\nb")}).Render(w)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "data: a\ndata: b\n\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	err = (SSEvent{Data: make(chan int)}).Render(w)
+	assert.NotEqual(t, nil, err)
+}
+
 func TestRenderStringLenZero(t *testing.T) {
 	w := httptest.NewRecorder()
 
@@ -0,0 +1,78 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package render
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SSEvent contains a Server-Sent Event.
+// A string or []byte Data is sent as is, split in one data line per line,
+// any other value is encoded as JSON.
+type SSEvent struct {
+	Event string
+	ID    string
+	Retry uint
+	Data  interface{}
+}
+
+var sseContentType = []string{"text/event-stream"}
+
+var fieldReplacer = strings.NewReplacer("\n", "", "\r", "")
+
+var dataReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Render (SSEvent) writes the event and flushes it to the client.
+func (r SSEvent) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	var buf bytes.Buffer
+	if r.ID != "" {
+		buf.WriteString("id: " + fieldReplacer.Replace(r.ID) + "\n")
+	}
+	if r.Event != "" {
+		buf.WriteString("event: " + fieldReplacer.Replace(r.Event) + "\n")
+	}
+	if r.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatUint(uint64(r.Retry), 10) + "\n")
+	}
+
+	var data string
+	switch d := r.Data.(type) {
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		jsonBytes, err := JSONMarshal(r.Data)
+		if err != nil {
+			return err
+		}
+		data = string(jsonBytes)
+	}
+	for _, line := range strings.Split(dataReplacer.Replace(data), "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteByte('\n')
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// WriteContentType (SSEvent) writes the event-stream ContentType and disables caching.
+func (r SSEvent) WriteContentType(w http.ResponseWriter) {
+	writeContentType(w, sseContentType)
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+}
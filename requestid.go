@@ -0,0 +1,60 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIDKey is the context key holding the id set by the RequestID middleware.
+const RequestIDKey = "_gin-gonic/gin/requestidkey"
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// RequestID returns a middleware giving each request an id, the X-Request-ID header
+// of the request or else a random UUID. The id is echoed in the X-Request-ID header
+// of the response and the handlers read it with c.RequestID.
+// The ids longer than 128 bytes are replaced by a random one.
+func RequestID() HandlerFunc {
+	return func(c *Context) {
+		id := c.requestHeader(requestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(requestIDHeader, id)
+	}
+}
+
+// RequestID returns the id set by the RequestID middleware, or "" without it.
+func (c *Context) RequestID() string {
+	return c.GetString(RequestIDKey)
+}
+
+// newRequestID returns a random (version 4) UUID.
+func newRequestID() string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		panic(err)
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+	return string(buf[:])
+}
@@ -0,0 +1,44 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	router := New()
+	router.Use(RequestID())
+	router.GET("/", func(c *Context) {
+		c.String(http.StatusOK, c.RequestID())
+	})
+
+	w := performRequest(router, http.MethodGet, "/", header{Key: "X-Request-ID", Value: "abc-123"})
+	assert.Equal(t, "abc-123", w.Body.String())
+	assert.Equal(t, "abc-123", w.Header().Get("X-Request-ID"))
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	w = performRequest(router, http.MethodGet, "/")
+	id := w.Header().Get("X-Request-ID")
+	assert.MatchRegex(t, id, uuid)
+	assert.Equal(t, id, w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/")
+	assert.NotEqual(t, id, w.Header().Get("X-Request-ID"))
+
+	w = performRequest(router, http.MethodGet, "/", header{Key: "X-Request-ID", Value: strings.Repeat("x", 129)})
+	assert.MatchRegex(t, w.Header().Get("X-Request-ID"), uuid)
+}
+
+func TestContextRequestIDMissing(t *testing.T) {
+	c, _ := CreateTestContext(httptest.NewRecorder())
+	assert.Equal(t, "", c.RequestID())
+}
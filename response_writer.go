@@ -47,6 +47,9 @@ type responseWriter struct {
 	http.ResponseWriter
 	size   int
 	status int
+
+	// beforeWrite are the OnResponse hooks, called once before the header is written.
+	beforeWrite []func()
 }
 
 var _ ResponseWriter = &responseWriter{}
@@ -55,6 +58,7 @@ func (w *responseWriter) reset(writer http.ResponseWriter) {
 	w.ResponseWriter = writer
 	w.size = noWritten
 	w.status = defaultStatus
+	w.beforeWrite = nil
 }
 
 func (w *responseWriter) WriteHeader(code int) {
@@ -68,6 +72,11 @@ func (w *responseWriter) WriteHeader(code int) {
 
 func (w *responseWriter) WriteHeaderNow() {
 	if !w.Written() {
+		hooks := w.beforeWrite
+		w.beforeWrite = nil
+		for i := len(hooks) - 1; i >= 0; i-- {
+			hooks[i]()
+		}
 		w.size = 0
 		w.ResponseWriter.WriteHeader(w.status)
 	}
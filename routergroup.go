@@ -5,6 +5,7 @@
 package gin
 
 import (
+	"mime"
 	"net/http"
 	"path"
 	"regexp"
@@ -23,6 +24,7 @@ type IRoutes interface {
 
 	Handle(string, string, ...HandlerFunc) IRoutes
 	Any(string, ...HandlerFunc) IRoutes
+	Match([]string, string, ...HandlerFunc) IRoutes
 	GET(string, ...HandlerFunc) IRoutes
 	POST(string, ...HandlerFunc) IRoutes
 	DELETE(string, ...HandlerFunc) IRoutes
@@ -34,6 +36,8 @@ type IRoutes interface {
 	StaticFile(string, string) IRoutes
 	Static(string, string) IRoutes
 	StaticFS(string, http.FileSystem) IRoutes
+
+	WithMeta(string, interface{}) IRoutes
 }
 
 // RouterGroup is used internally to configure router, a RouterGroup is associated with
@@ -43,6 +47,13 @@ type RouterGroup struct {
 	basePath string
 	engine   *Engine
 	root     bool
+	// number of engine middleware at the head of Handlers, see Engine.Rebuild.
+	globals int
+
+	// routes registered by the last call, see WithMeta.
+	lastRoutes []routeKey
+	// overrides the engine RedirectTrailingSlash when set, see RedirectTrailingSlash.
+	trailingSlash *bool
 }
 
 var _ IRouter = &RouterGroup{}
@@ -60,9 +71,20 @@ func (group *RouterGroup) Group(relativePath string, handlers ...HandlerFunc) *R
 		Handlers: group.combineHandlers(handlers),
 		basePath: group.calculateAbsolutePath(relativePath),
 		engine:   group.engine,
+		globals:  group.globalHandlers(),
+
+		trailingSlash: group.trailingSlash,
 	}
 }
 
+// RedirectTrailingSlash overrides the engine RedirectTrailingSlash for the routes registered
+// afterwards in the group and its subgroups, e.g. api.RedirectTrailingSlash(false) makes both
+// "/api/foo" and "/api/foo/" answer 404 when only the other one is registered.
+func (group *RouterGroup) RedirectTrailingSlash(enabled bool) *RouterGroup {
+	group.trailingSlash = &enabled
+	return group
+}
+
 // BasePath returns the base path of router group.
 // For example, if v := router.Group("/rest/n/v1/api"), v.BasePath() is "/rest/n/v1/api".
 func (group *RouterGroup) BasePath() string {
@@ -70,9 +92,19 @@ func (group *RouterGroup) BasePath() string {
 }
 
 func (group *RouterGroup) handle(httpMethod, relativePath string, handlers HandlersChain) IRoutes {
+	return group.handleMethods(relativePath, handlers, httpMethod)
+}
+
+func (group *RouterGroup) handleMethods(relativePath string, handlers HandlersChain, httpMethods ...string) IRoutes {
 	absolutePath := group.calculateAbsolutePath(relativePath)
 	handlers = group.combineHandlers(handlers)
-	group.engine.addRoute(httpMethod, absolutePath, handlers)
+	group.engine.trackChain(handlers, group.globalHandlers())
+	group.lastRoutes = group.lastRoutes[:0]
+	for _, httpMethod := range httpMethods {
+		group.engine.addRoute(httpMethod, absolutePath, handlers)
+		group.lastRoutes = append(group.lastRoutes, routeKey{method: httpMethod, path: absolutePath})
+		group.markTrailingSlash(routeKey{method: httpMethod, path: absolutePath})
+	}
 	return group.returnObj()
 }
 
@@ -87,12 +119,40 @@ func (group *RouterGroup) handle(httpMethod, relativePath string, handlers Handl
 // frequently used, non-standardized or custom methods (e.g. for internal
 // communication with a proxy).
 func (group *RouterGroup) Handle(httpMethod, relativePath string, handlers ...HandlerFunc) IRoutes {
-	if matches, err := regexp.MatchString("^[A-Z]+$", httpMethod); !matches || err != nil {
-		panic("http method " + httpMethod + " is not valid")
-	}
+	assertValidMethod(httpMethod)
 	return group.handle(httpMethod, relativePath, handlers)
 }
 
+// HandleClean is like Handle but the route only runs the engine middleware and the given handlers,
+// the middleware added to the group and its parents is skipped, e.g. a health check inside an
+// authenticated group: api.HandleClean("GET", "/health", healthz).
+func (group *RouterGroup) HandleClean(httpMethod, relativePath string, handlers ...HandlerFunc) IRoutes {
+	assertValidMethod(httpMethod)
+	absolutePath := group.calculateAbsolutePath(relativePath)
+	handlers = group.engine.combineHandlers(handlers)
+	group.engine.trackChain(handlers, len(group.engine.Handlers))
+	group.engine.addRoute(httpMethod, absolutePath, handlers)
+	group.lastRoutes = append(group.lastRoutes[:0], routeKey{method: httpMethod, path: absolutePath})
+	group.markTrailingSlash(routeKey{method: httpMethod, path: absolutePath})
+	return group.returnObj()
+}
+
+func (group *RouterGroup) markTrailingSlash(route routeKey) {
+	if group.trailingSlash == nil {
+		return
+	}
+	engine := group.engine
+	if engine.trailingSlash == nil {
+		engine.trailingSlash = make(map[routeKey]bool)
+	}
+	// an optional param registers the route with and without it
+	if base, ok := optionalParamBase(route.path); ok {
+		engine.trailingSlash[routeKey{method: route.method, path: base}] = *group.trailingSlash
+		route.path = route.path[:len(route.path)-1]
+	}
+	engine.trailingSlash[route] = *group.trailingSlash
+}
+
 // POST is a shortcut for router.Handle("POST", path, handle).
 func (group *RouterGroup) POST(relativePath string, handlers ...HandlerFunc) IRoutes {
 	return group.handle(http.MethodPost, relativePath, handlers)
@@ -131,15 +191,36 @@ func (group *RouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) IRo
 // Any registers a route that matches all the HTTP methods.
 // GET, POST, PUT, PATCH, HEAD, OPTIONS, DELETE, CONNECT, TRACE.
 func (group *RouterGroup) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
-	group.handle(http.MethodGet, relativePath, handlers)
-	group.handle(http.MethodPost, relativePath, handlers)
-	group.handle(http.MethodPut, relativePath, handlers)
-	group.handle(http.MethodPatch, relativePath, handlers)
-	group.handle(http.MethodHead, relativePath, handlers)
-	group.handle(http.MethodOptions, relativePath, handlers)
-	group.handle(http.MethodDelete, relativePath, handlers)
-	group.handle(http.MethodConnect, relativePath, handlers)
-	group.handle(http.MethodTrace, relativePath, handlers)
+	return group.handleMethods(relativePath, handlers,
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodHead,
+		http.MethodOptions,
+		http.MethodDelete,
+		http.MethodConnect,
+		http.MethodTrace,
+	)
+}
+
+// Match registers a route that matches the given HTTP methods, e.g.
+// router.Match([]string{"GET", "POST"}, "/search", search).
+func (group *RouterGroup) Match(httpMethods []string, relativePath string, handlers ...HandlerFunc) IRoutes {
+	for _, httpMethod := range httpMethods {
+		assertValidMethod(httpMethod)
+	}
+	return group.handleMethods(relativePath, handlers, httpMethods...)
+}
+
+// WithMeta attaches a key/value pair to the routes registered by the last call
+// on this group, so middleware can read it with Context.RouteMeta. For example:
+//     router.GET("/admin", handler).WithMeta("scopes", []string{"admin"})
+func (group *RouterGroup) WithMeta(key string, value interface{}) IRoutes {
+	assert1(len(group.lastRoutes) > 0, "WithMeta must follow the registration of a route")
+	for _, route := range group.lastRoutes {
+		group.engine.setRouteMeta(route, key, value)
+	}
 	return group.returnObj()
 }
 
@@ -152,9 +233,7 @@ func (group *RouterGroup) StaticFile(relativePath, filepath string) IRoutes {
 	handler := func(c *Context) {
 		c.File(filepath)
 	}
-	group.GET(relativePath, handler)
-	group.HEAD(relativePath, handler)
-	return group.returnObj()
+	return group.handleMethods(relativePath, HandlersChain{handler}, http.MethodGet, http.MethodHead)
 }
 
 // Static serves files from the given file system root.
@@ -170,19 +249,28 @@ func (group *RouterGroup) Static(relativePath, root string) IRoutes {
 // StaticFS works just like `Static()` but a custom `http.FileSystem` can be used instead.
 // Gin by default user: gin.Dir()
 func (group *RouterGroup) StaticFS(relativePath string, fs http.FileSystem) IRoutes {
+	return group.staticFS(relativePath, fs, false)
+}
+
+// StaticFSGzip works just like `StaticFS()` but the text files, e.g. HTML, CSS, JavaScript
+// or JSON, are gzipped on the fly for the clients accepting it. The other files, such as the
+// images, are already compressed and served as is.
+func (group *RouterGroup) StaticFSGzip(relativePath string, fs http.FileSystem) IRoutes {
+	return group.staticFS(relativePath, fs, true)
+}
+
+func (group *RouterGroup) staticFS(relativePath string, fs http.FileSystem, gzipped bool) IRoutes {
 	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
 		panic("URL parameters can not be used when serving a static folder")
 	}
-	handler := group.createStaticHandler(relativePath, fs)
+	handler := group.createStaticHandler(relativePath, fs, gzipped)
 	urlPattern := path.Join(relativePath, "/*filepath")
 
 	// Register GET and HEAD handlers
-	group.GET(urlPattern, handler)
-	group.HEAD(urlPattern, handler)
-	return group.returnObj()
+	return group.handleMethods(urlPattern, HandlersChain{handler}, http.MethodGet, http.MethodHead)
 }
 
-func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileSystem) HandlerFunc {
+func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileSystem, gzipped bool) HandlerFunc {
 	absolutePath := group.calculateAbsolutePath(relativePath)
 	fileServer := http.StripPrefix(absolutePath, http.FileServer(fs))
 
@@ -201,12 +289,33 @@ func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileS
 			c.index = -1
 			return
 		}
-		f.Close()
+		defer f.Close()
+		info, err := f.Stat()
+		if err == nil && gzipped && !info.IsDir() {
+			if contentType := mime.TypeByExtension(path.Ext(info.Name())); compressible(contentType) {
+				c.Writer.Header().Add("Vary", "Accept-Encoding")
+				if acceptsGzip(c.requestHeader("Accept-Encoding")) {
+					c.writeGzip(contentType, f)
+					return
+				}
+			}
+		}
+		if err == nil {
+			setFileETag(c.Writer, info)
+		}
 
 		fileServer.ServeHTTP(c.Writer, c.Request)
 	}
 }
 
+// NoRoute adds handlers for the unknown paths under the group prefix, they take
+// precedence over the engine NoRoute handlers. It return a 404 code by default.
+func (group *RouterGroup) NoRoute(handlers ...HandlerFunc) {
+	handlers = group.combineHandlers(handlers)
+	group.engine.trackChain(handlers, group.globalHandlers())
+	group.engine.addGroupNoRoute(group.BasePath(), handlers)
+}
+
 func (group *RouterGroup) combineHandlers(handlers HandlersChain) HandlersChain {
 	finalSize := len(group.Handlers) + len(handlers)
 	if finalSize >= int(abortIndex) {
@@ -218,6 +327,14 @@ func (group *RouterGroup) combineHandlers(handlers HandlersChain) HandlersChain
 	return mergedHandlers
 }
 
+// globalHandlers returns how many engine middleware are at the head of the group Handlers.
+func (group *RouterGroup) globalHandlers() int {
+	if group.root {
+		return len(group.Handlers)
+	}
+	return group.globals
+}
+
 func (group *RouterGroup) calculateAbsolutePath(relativePath string) string {
 	return joinPaths(group.basePath, relativePath)
 }
@@ -228,3 +345,11 @@ func (group *RouterGroup) returnObj() IRoutes {
 	}
 	return group
 }
+
+var validMethod = regexp.MustCompile("^[A-Z]+$")
+
+func assertValidMethod(httpMethod string) {
+	if !validMethod.MatchString(httpMethod) {
+		panic("http method " + httpMethod + " is not valid")
+	}
+}
@@ -6,6 +6,7 @@ package gin
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/go-playground/assert"
@@ -150,6 +151,30 @@ func TestRouterGroupBadMethod(t *testing.T) {
 	})
 }
 
+func TestRouterGroupMatch(t *testing.T) {
+	router := New()
+	v1 := router.Group("/v1")
+	v1.Match([]string{http.MethodGet, http.MethodPost}, "/search", func(c *Context) {
+		c.String(http.StatusOK, c.Request.Method)
+	})
+
+	w := performRequest(router, http.MethodGet, "/v1/search")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.MethodGet, w.Body.String())
+	w = performRequest(router, http.MethodPost, "/v1/search")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.MethodPost, w.Body.String())
+	w = performRequest(router, http.MethodPut, "/v1/search")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	Panics(t, func() {
+		router.Match([]string{http.MethodGet, "get"}, "/lower")
+	})
+	// nothing is registered when a method is invalid
+	w = performRequest(router, http.MethodGet, "/lower")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestRouterGroupPipeline(t *testing.T) {
 	router := New()
 	testRoutesInterface(t, router)
@@ -164,6 +189,7 @@ func testRoutesInterface(t *testing.T, r IRoutes) {
 
 	assert.Equal(t, r == r.Handle(http.MethodGet, "/handler", handler), true)
 	assert.Equal(t, true, r == r.Any("/any", handler))
+	assert.Equal(t, true, r == r.Match([]string{http.MethodGet, http.MethodPost}, "/match", handler))
 	assert.Equal(t, true, r == r.GET("/", handler))
 	assert.Equal(t, true, r == r.POST("/", handler))
 	assert.Equal(t, true, r == r.DELETE("/", handler))
@@ -175,4 +201,114 @@ func testRoutesInterface(t *testing.T, r IRoutes) {
 	assert.Equal(t, true, r == r.StaticFile("/file", "."))
 	assert.Equal(t, true, r == r.Static("/static", "."))
 	assert.Equal(t, true, r == r.StaticFS("/static2", Dir(".", false)))
+	assert.Equal(t, true, r == r.GET("/meta", handler).WithMeta("key", "value"))
+}
+
+func TestRouterGroupWithMeta(t *testing.T) {
+	router := New()
+	router.Use(func(c *Context) {
+		scopes, ok := c.RouteMeta("scopes")
+		if !ok {
+			c.Header("X-Scopes", "none")
+			return
+		}
+		c.Header("X-Scopes", strings.Join(scopes.([]string), ","))
+	})
+
+	handler := func(c *Context) {}
+	router.GET("/public", handler)
+	router.GET("/admin", handler).WithMeta("scopes", []string{"read", "write"})
+	v1 := router.Group("/v1")
+	v1.Any("/items", handler).WithMeta("scopes", []string{"read"})
+	v1.GET("/posts/:id?", handler).WithMeta("scopes", []string{"posts"})
+
+	w := performRequest(router, http.MethodGet, "/public")
+	assert.Equal(t, "none", w.Header().Get("X-Scopes"))
+
+	w = performRequest(router, http.MethodGet, "/admin")
+	assert.Equal(t, "read,write", w.Header().Get("X-Scopes"))
+
+	w = performRequest(router, http.MethodPost, "/v1/items")
+	assert.Equal(t, "read", w.Header().Get("X-Scopes"))
+
+	w = performRequest(router, http.MethodDelete, "/v1/items")
+	assert.Equal(t, "read", w.Header().Get("X-Scopes"))
+
+	w = performRequest(router, http.MethodGet, "/v1/posts")
+	assert.Equal(t, "posts", w.Header().Get("X-Scopes"))
+
+	w = performRequest(router, http.MethodGet, "/v1/posts/1")
+	assert.Equal(t, "posts", w.Header().Get("X-Scopes"))
+
+	w = performRequest(router, http.MethodGet, "/notfound")
+	assert.Equal(t, "none", w.Header().Get("X-Scopes"))
+
+	assert.PanicMatches(t, func() {
+		router.Group("/v2").WithMeta("scopes", []string{"read"})
+	}, "WithMeta must follow the registration of a route")
+}
+
+func TestRouterGroupHandleClean(t *testing.T) {
+	var trace string
+	router := New()
+	router.Use(func(c *Context) { trace += "E" })
+	api := router.Group("/api", func(c *Context) { trace += "A" })
+	v1 := api.Group("/v1/", func(c *Context) { trace += "V" })
+	v1.GET("/users", func(c *Context) { trace += "u" })
+	v1.HandleClean("GET", "/health", func(c *Context) { trace += "h" }).WithMeta("public", true)
+
+	w := performRequest(router, "GET", "/api/v1/health")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Eh", trace)
+
+	trace = ""
+	performRequest(router, "GET", "/api/v1/users")
+	assert.Equal(t, "EAVu", trace)
+
+	assert.Equal(t, H{"public": true}, router.routesMeta[routeKey{method: "GET", path: "/api/v1/health"}])
+
+	Panics(t, func() {
+		v1.HandleClean("get", "/health2")
+	})
+}
+
+func TestRouterGroupNoRoute(t *testing.T) {
+	router := New()
+	router.NoRoute(func(c *Context) {
+		c.String(http.StatusNotFound, "global")
+	})
+	v1 := router.Group("/v1")
+	v1.GET("/users", func(c *Context) {})
+	v2 := router.Group("/v2/", func(c *Context) {
+		c.Header("X-Version", "2")
+	})
+	v2.GET("/users", func(c *Context) {})
+	v2.NoRoute(func(c *Context) {
+		c.JSON(http.StatusNotFound, H{"error": "not found", "version": 2})
+	})
+	admin := v2.Group("/admin")
+	admin.NoRoute()
+
+	w := performRequest(router, "GET", "/v2/unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"error":"not found","version":2}`, w.Body.String())
+	assert.Equal(t, "2", w.Header().Get("X-Version"))
+
+	w = performRequest(router, "GET", "/v2")
+	assert.Equal(t, `{"error":"not found","version":2}`, w.Body.String())
+
+	w = performRequest(router, "GET", "/v2/admin/unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 page not found", w.Body.String())
+	assert.Equal(t, "2", w.Header().Get("X-Version"))
+
+	w = performRequest(router, "GET", "/v1/unknown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "global", w.Body.String())
+
+	w = performRequest(router, "GET", "/v2x/unknown")
+	assert.Equal(t, "global", w.Body.String())
+
+	w = performRequest(router, "GET", "/v2/users")
+	assert.Equal(t, http.StatusOK, w.Code)
 }
@@ -5,6 +5,7 @@
 package gin
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -198,6 +199,36 @@ func TestRouteRedirectTrailingSlash(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+func TestRouteRedirectTrailingSlashGroup(t *testing.T) {
+	router := New()
+	router.RedirectFixedPath = false
+	router.GET("/path/", func(c *Context) {})
+
+	api := router.Group("/api").RedirectTrailingSlash(false)
+	api.GET("/users", func(c *Context) {})
+	api.Group("/v2").GET("/users/:id/", func(c *Context) {})
+
+	w := performRequest(router, http.MethodGet, "/path")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/path/", w.Header().Get("Location"))
+
+	w = performRequest(router, http.MethodGet, "/api/users/")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = performRequest(router, http.MethodGet, "/api/users")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, http.MethodGet, "/api/v2/users/42")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	router.RedirectTrailingSlash = false
+	legacy := router.Group("/legacy").RedirectTrailingSlash(true)
+	legacy.GET("/users", func(c *Context) {})
+	w = performRequest(router, http.MethodGet, "/legacy/users/")
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	w = performRequest(router, http.MethodGet, "/path")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestRouteRedirectFixedPath(t *testing.T) {
 	router := New()
 	router.RedirectFixedPath = true
@@ -327,6 +358,71 @@ func TestRouteStaticFile(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w3.Code)
 }
 
+func TestRouteStaticFileETag(t *testing.T) {
+	router := New()
+	router.Static("/static", "./")
+	router.StaticFS("/fs", Dir("./", false))
+	router.GET("/file", func(c *Context) {
+		c.File("./gin.go")
+	})
+	router.GET("/fromfs", func(c *Context) {
+		c.FileFromFS("gin.go", http.Dir("./"))
+	})
+
+	info, err := os.Stat("./gin.go")
+	assert.Equal(t, nil, err)
+	etag := fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano())
+
+	for _, path := range []string{"/static/gin.go", "/fs/gin.go", "/file", "/fromfs"} {
+		w := performRequest(router, http.MethodGet, path)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+
+		w = performRequest(router, http.MethodGet, path, header{Key: "If-None-Match", Value: etag})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, "", w.Body.String())
+
+		w = performRequest(router, http.MethodGet, path, header{Key: "If-None-Match", Value: `W/"0-0"`})
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
+
+func TestRouteStaticFSGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gin-gzip")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log('gin')"), 0600))
+	assert.Equal(t, nil, ioutil.WriteFile(filepath.Join(dir, "logo.png"), []byte("\x89PNG"), 0600))
+
+	router := New()
+	router.StaticFSGzip("/assets", Dir(dir, false))
+
+	w := performRequest(router, http.MethodGet, "/assets/app.js", header{Key: "Accept-Encoding", Value: "gzip, deflate"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	Contains(t, w.Header().Get("Content-Type"), "javascript")
+	gz, err := gzip.NewReader(w.Body)
+	assert.Equal(t, nil, err)
+	body, err := ioutil.ReadAll(gz)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "console.log('gin')", string(body))
+
+	w = performRequest(router, http.MethodGet, "/assets/app.js")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	assert.Equal(t, "console.log('gin')", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/assets/logo.png", header{Key: "Accept-Encoding", Value: "gzip"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "\x89PNG", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/assets/missing.js", header{Key: "Accept-Encoding", Value: "gzip"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
 // TestHandleStaticDir - ensure the root/sub dir handles properly
 func TestRouteStaticListingDir(t *testing.T) {
 	router := New()
@@ -384,6 +480,58 @@ func TestRouteNotAllowedEnabled(t *testing.T) {
 	assert.Equal(t, http.StatusTeapot, w.Code)
 }
 
+func TestRouteNegotiatedNoRoute(t *testing.T) {
+	router := New()
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(NegotiatedNoRoute())
+	router.NoMethod(NegotiatedNoRoute())
+	router.GET("/path", func(c *Context) {})
+
+	w := performRequest(router, http.MethodGet, "/missing", header{Key: "Accept", Value: "application/json"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"error":"not found"}`, w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	w = performRequest(router, http.MethodGet, "/missing", header{Key: "Accept", Value: "text/plain"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 page not found", w.Body.String())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+
+	w = performRequest(router, http.MethodGet, "/missing")
+	assert.Equal(t, "404 page not found", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/missing", header{Key: "Accept", Value: "text/plainx"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 page not found", w.Body.String())
+
+	w = performRequest(router, http.MethodPost, "/path", header{Key: "Accept", Value: "text/html;q=0.5, application/json"})
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, `{"error":"method not allowed"}`, w.Body.String())
+}
+
+func TestRouteNotAllowedAllowHeader(t *testing.T) {
+	router := New()
+	router.HandleMethodNotAllowed = true
+	router.GET("/path", func(c *Context) {})
+	router.PUT("/path", func(c *Context) {})
+	router.POST("/other", func(c *Context) {})
+	w := performRequest(router, http.MethodPost, "/path")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, PUT", w.Header().Get("Allow"))
+
+	var allow string
+	router.Use(func(c *Context) {
+		allow = c.Writer.Header().Get("Allow")
+	})
+	router.NoMethod(func(c *Context) {
+		c.String(http.StatusTeapot, "responseText")
+	})
+	w = performRequest(router, http.MethodPost, "/path")
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Equal(t, "GET, PUT", allow)
+	assert.Equal(t, "GET, PUT", w.Header().Get("Allow"))
+}
+
 func TestRouteNotAllowedEnabled2(t *testing.T) {
 	router := New()
 	router.HandleMethodNotAllowed = true
@@ -619,3 +767,62 @@ func TestRouteContextHoldsFullPath(t *testing.T) {
 	w := performRequest(router, http.MethodGet, "/not-found")
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestRouteCatchAllWithStaticSibling(t *testing.T) {
+	router := New()
+	router.GET("/files/*path", func(c *Context) {
+		c.String(http.StatusOK, "catch-all "+c.Param("path"))
+	})
+	router.GET("/files/info", func(c *Context) {
+		c.String(http.StatusOK, "info")
+	})
+
+	w := performRequest(router, http.MethodGet, "/files/info")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "info", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/files/a/b")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "catch-all /a/b", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/files/info/c")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "catch-all /info/c", w.Body.String())
+}
+
+func TestRouteOptionalParam(t *testing.T) {
+	router := New()
+	router.GET("/posts/:id?", func(c *Context) {
+		id, ok := c.Params.Get("id")
+		c.String(http.StatusOK, "%s %t", id, ok)
+	})
+
+	w := performRequest(router, http.MethodGet, "/posts")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, " false", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/posts/42")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "42 true", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/posts/42/comments")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRouteMultipleParamsInSegment(t *testing.T) {
+	router := New()
+	router.GET("/files/:name.:ext", func(c *Context) {
+		c.String(http.StatusOK, "%s %s", c.Param("name"), c.Param("ext"))
+	})
+
+	w := performRequest(router, http.MethodGet, "/files/report.pdf")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "report pdf", w.Body.String())
+
+	w = performRequest(router, http.MethodGet, "/files/report")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	routes := router.Routes()
+	assert.Equal(t, 1, len(routes))
+	assert.Equal(t, "/files/:name.:ext", routes[0].Path)
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/manucorporat/gin-diet/internal/jsonschema"
+)
+
+// ResponseSchemaKey is the route metadata key holding the JSON schema ([]byte) the route
+// responses are validated against when Engine.ValidateResponseSchemas is enabled in debug mode.
+const ResponseSchemaKey = "_gin-gonic/gin/responseschemakey"
+
+// defaultSchemaBodyBytes is the body limit of ValidateJSONSchema.
+const defaultSchemaBodyBytes = 10 << 20 // 10 MB
+
+// ValidateJSONSchema returns a middleware that validates the request body against a JSON schema
+// before the handler runs, a non-conforming body is aborted with 400 and the list of violations.
+// The body is restored afterwards, so the handlers can bind it as usual.
+// The bodies longer than 10 MB are aborted with 413, see ValidateJSONSchemaWithLimit.
+// It panics if the schema is invalid.
+func ValidateJSONSchema(schema []byte) HandlerFunc {
+	return ValidateJSONSchemaWithLimit(schema, defaultSchemaBodyBytes)
+}
+
+// ValidateJSONSchemaWithLimit is like ValidateJSONSchema but the bodies longer than
+// maxBytes are aborted with 413.
+func ValidateJSONSchemaWithLimit(schema []byte, maxBytes int64) HandlerFunc {
+	s := jsonschema.MustCompile(schema)
+	return func(c *Context) {
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = ioutil.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1)); err != nil {
+				c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind)
+				return
+			}
+			if int64(len(body)) > maxBytes {
+				c.AbortWithError(http.StatusRequestEntityTooLarge, ErrBodyTooLarge).SetType(ErrorTypeBind)
+				return
+			}
+		}
+		if errs := s.ValidateBytes(body); len(errs) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, H{"errors": errs})
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		c.Set(BodyBytesKey, body)
+	}
+}
+
+// nextValidatingResponse runs the handlers, recording the response body to
+// validate it against the route schema once they are done.
+func (engine *Engine) nextValidatingResponse(c *Context) {
+	meta, ok := c.RouteMeta(ResponseSchemaKey)
+	if !ok {
+		c.Next()
+		return
+	}
+
+	w := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	if !strings.Contains(filterFlags(w.Header().Get("Content-Type")), "json") {
+		return
+	}
+	schema, err := engine.responseSchema(meta)
+	if err != nil {
+		debugPrint("[WARNING] invalid response schema of %s %s: %v", c.Request.Method, c.FullPath(), err)
+		return
+	}
+	if errs := schema.ValidateBytes(w.body.Bytes()); len(errs) > 0 {
+		debugPrint("[WARNING] response of %s %s does not match its schema:\n\t%s",
+			c.Request.Method, c.FullPath(), strings.Join(errs, "\n\t"))
+	}
+}
+
+func (engine *Engine) responseSchema(meta interface{}) (*jsonschema.Schema, error) {
+	data, ok := meta.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a []byte", meta)
+	}
+	if schema, ok := engine.responseSchemas.Load(string(data)); ok {
+		return schema.(*jsonschema.Schema), nil
+	}
+	schema, err := jsonschema.Compile(data)
+	if err != nil {
+		return nil, err
+	}
+	engine.responseSchemas.Store(string(data), schema)
+	return schema, nil
+}
+
+// recordingWriter keeps a copy of the response body.
+type recordingWriter struct {
+	ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 Manu Martinez-Almeida.  All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package gin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+var testUserSchema = []byte(`{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0}
+	}
+}`)
+
+func TestValidateJSONSchema(t *testing.T) {
+	var user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	router := New()
+	router.POST("/users", ValidateJSONSchema(testUserSchema), func(c *Context) {
+		if err := c.ShouldBindJSON(&user); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.String(http.StatusCreated, user.Name)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name": "manu", "age": 30}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "manu", w.Body.String())
+	assert.Equal(t, 30, user.Age)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/users", bytes.NewBufferString(`{"age": -1.5}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"errors":["/: missing required property \"name\"","/age: expected integer, got number"]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name": `))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestValidateJSONSchemaWithLimit(t *testing.T) {
+	router := New()
+	router.POST("/users", ValidateJSONSchemaWithLimit(testUserSchema, 16), func(c *Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name": "gin"}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/users", bytes.NewBufferString(`{"name": "manucorporat"}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestValidateJSONSchemaInvalidSchema(t *testing.T) {
+	assert.PanicMatches(t, func() {
+		ValidateJSONSchema([]byte(`{"type": 1}`))
+	}, "jsonschema: invalid type 1")
+}
+
+func TestValidateResponseSchemas(t *testing.T) {
+	router := New()
+	router.ValidateResponseSchemas = true
+	router.GET("/users/:name", func(c *Context) {
+		c.JSON(http.StatusOK, H{"name": c.Param("name"), "age": "unknown"})
+	}).WithMeta(ResponseSchemaKey, testUserSchema)
+	router.GET("/text", func(c *Context) {
+		c.String(http.StatusOK, "not json")
+	}).WithMeta(ResponseSchemaKey, testUserSchema)
+	router.GET("/invalid", func(c *Context) {
+		c.JSON(http.StatusOK, H{})
+	}).WithMeta(ResponseSchemaKey, "not bytes")
+
+	SetMode(DebugMode)
+	defer SetMode(TestMode)
+
+	var w *httptest.ResponseRecorder
+	output := captureOutput(t, func() {
+		w = performRequest(router, "GET", "/users/manu")
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"age":"unknown","name":"manu"}`, w.Body.String())
+	assert.Equal(t, "[GIN-debug] [WARNING] response of GET /users/:name does not match its schema:\n\t/age: expected integer, got string\n", output)
+
+	output = captureOutput(t, func() {
+		w = performRequest(router, "GET", "/text")
+	})
+	assert.Equal(t, "not json", w.Body.String())
+	assert.Equal(t, "", output)
+
+	output = captureOutput(t, func() {
+		performRequest(router, "GET", "/invalid")
+	})
+	assert.Equal(t, "[GIN-debug] [WARNING] invalid response schema of GET /invalid: string is not a []byte\n", output)
+
+	SetMode(ReleaseMode)
+	output = captureOutput(t, func() {
+		w = performRequest(router, "GET", "/users/manu")
+	})
+	assert.Equal(t, `{"age":"unknown","name":"manu"}`, w.Body.String())
+	assert.Equal(t, "", output)
+}
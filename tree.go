@@ -128,9 +128,61 @@ func (n *node) incrementChildPrio(pos int) int {
 	return newPos
 }
 
+// walk calls fn for n and all the nodes below it.
+func (n *node) walk(fn func(*node)) {
+	fn(n)
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
+// addChild adds a static child node, keeping the wildcard child (if any)
+// at the end of the children list.
+func (n *node) addChild(child *node) {
+	if n.wildChild && len(n.children) > 0 {
+		wildcardChild := n.children[len(n.children)-1]
+		n.children = append(n.children[:len(n.children)-1], child, wildcardChild)
+	} else {
+		n.children = append(n.children, child)
+	}
+}
+
+// isCatchAllSibling reports whether path is a static route that can be
+// registered alongside the catch-all held by n, e.g. /src/AUTHORS next to
+// /src/*filepath.
+func isCatchAllSibling(n *node, path string) bool {
+	if n.nType != catchAll || len(path) == 0 || path[0] != '/' {
+		return false
+	}
+	return len(path) == 1 || (path[1] != ':' && path[1] != '*')
+}
+
+// optionalParamBase returns the path without its trailing optional param,
+// e.g. /posts for /posts/:id?, and whether the path ends with one.
+func optionalParamBase(path string) (string, bool) {
+	if path == "" || path[len(path)-1] != '?' {
+		return "", false
+	}
+	i := strings.LastIndexByte(path, '/')
+	if i < 0 || path[i+1] != ':' {
+		return "", false
+	}
+	if i == 0 {
+		return "/", true
+	}
+	return path[:i], true
+}
+
 // addRoute adds a node with the given handle to the path.
+// A trailing optional param, e.g. /posts/:id?, adds the handle for both
+// /posts and /posts/:id.
 // Not concurrency-safe!
 func (n *node) addRoute(path string, handlers HandlersChain) {
+	if base, ok := optionalParamBase(path); ok {
+		n.addRoute(base, handlers)
+		path = path[:len(path)-1]
+	}
+
 	fullPath := path
 	n.priority++
 	numParams := countParams(path)
@@ -188,9 +240,9 @@ walk:
 		if i < len(path) {
 			path = path[i:]
 
-			if n.wildChild {
+			if n.wildChild && !isCatchAllSibling(n, path) {
 				parentFullPathIndex += len(n.path)
-				n = n.children[0]
+				n = n.children[len(n.children)-1]
 				n.priority++
 
 				// Update maxParams of the child node
@@ -205,6 +257,11 @@ walk:
 					if len(n.path) >= len(path) || path[len(n.path)] == '/' {
 						continue walk
 					}
+
+					// the same param followed by a separator, e.g. :name.:ext
+					if wildcard, _, _ := findWildcard(path); n.nType == param && wildcard == n.path {
+						continue walk
+					}
 				}
 
 				pathSeg := path
@@ -212,6 +269,18 @@ walk:
 					pathSeg = strings.SplitN(path, "/", 2)[0]
 				}
 				prefix := fullPath[:strings.Index(fullPath, pathSeg)] + n.path
+
+				// Two params at the same position must share the same name,
+				// otherwise the handlers would see different keys for the
+				// same path segment.
+				if n.nType == param && pathSeg[0] == ':' {
+					panic("param '" + pathSeg +
+						"' in new path '" + fullPath +
+						"' conflicts with existing param '" + n.path +
+						"' in existing prefix '" + prefix +
+						"', params at the same position must have the same name")
+				}
+
 				panic("'" + pathSeg +
 					"' in new path '" + fullPath +
 					"' conflicts with existing wildcard '" + n.path +
@@ -221,8 +290,19 @@ walk:
 
 			c := path[0]
 
-			// slash after param
-			if n.nType == param && c == '/' && len(n.children) == 1 {
+			// slash (or separator, e.g. /:name.:ext) after param
+			if n.nType == param && len(n.children) == 1 {
+				sep := n.children[0].separator()
+				if !strings.HasPrefix(path, sep) {
+					prefix := fullPath[:parentFullPathIndex] + n.path
+					panic("'" + path +
+						"' in new path '" + fullPath +
+						"' conflicts with existing '" + sep +
+						"' after param '" + n.path +
+						"' in existing prefix '" + prefix +
+						"'")
+				}
+
 				parentFullPathIndex += len(n.path)
 				n = n.children[0]
 				n.priority++
@@ -247,7 +327,7 @@ walk:
 					maxParams: numParams,
 					fullPath:  fullPath,
 				}
-				n.children = append(n.children, child)
+				n.addChild(child)
 				n.incrementChildPrio(len(n.indices) - 1)
 				n = child
 			}
@@ -264,8 +344,16 @@ walk:
 	}
 }
 
+// isParamNameChar reports whether c can be part of the name of a param which
+// shares its path segment with other params.
+func isParamNameChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
 // Search for a wildcard segment and check the name for invalid characters.
 // Returns -1 as index, if no wildcard war found.
+// Params sharing a segment, e.g. /:name.:ext, are returned one at a time, the
+// name of all but the last one is made of letters, digits and '_' only.
 func findWildcard(path string) (wildcard string, i int, valid bool) {
 	// Find start
 	for start, c := range []byte(path) {
@@ -281,6 +369,17 @@ func findWildcard(path string) (wildcard string, i int, valid bool) {
 			case '/':
 				return path[start : start+1+end], start, valid
 			case ':', '*':
+				// Several params can share a segment as long as a
+				// separator divides them
+				if valid && c == ':' && path[start] == ':' {
+					nameEnd := start + 1
+					for nameEnd < start+1+end && isParamNameChar(path[nameEnd]) {
+						nameEnd++
+					}
+					if nameEnd > start+1 && nameEnd < start+1+end {
+						return path[start:nameEnd], start, true
+					}
+				}
 				valid = false
 			}
 		}
@@ -308,9 +407,19 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 			panic("wildcards must be named with a non-empty name in path '" + fullPath + "'")
 		}
 
+		// optional params have been expanded by addRoute already
+		if wildcard[len(wildcard)-1] == '?' {
+			panic("optional params are only allowed as the last path segment in path '" + fullPath + "'")
+		}
+
+		// A catch-all placed right after an existing segment root, e.g.
+		// /src/*filepath after /src/AUTHORS, lives next to its static
+		// routes instead of conflicting with them.
+		staticSiblings := wildcard[0] == '*' && len(n.path) > 0 && n.path[len(n.path)-1] == '/'
+
 		// Check if this node has existing children which would be
 		// unreachable if we insert the wildcard here
-		if len(n.children) > 0 {
+		if len(n.children) > 0 && !staticSiblings {
 			panic("wildcard segment '" + wildcard +
 				"' conflicts with existing children in path '" + fullPath + "'")
 		}
@@ -336,6 +445,7 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 
 			// if the path doesn't end with the wildcard, then there
 			// will be another non-wildcard subpath starting with '/'
+			// (or with the separator of the next param in the segment)
 			if len(wildcard) < len(path) {
 				path = path[len(wildcard):]
 
@@ -359,13 +469,14 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 			panic("catch-all routes are only allowed at the end of the path in path '" + fullPath + "'")
 		}
 
-		if len(n.path) > 0 && n.path[len(n.path)-1] == '/' {
-			panic("catch-all conflicts with existing handle for the path segment root in path '" + fullPath + "'")
+		if staticSiblings {
+			n.insertCatchAllSibling(wildcard, fullPath, handlers)
+			return
 		}
 
 		// currently fixed width 1 for '/'
 		i--
-		if path[i] != '/' {
+		if i < 0 || path[i] != '/' {
 			panic("no / before catch-all in path '" + fullPath + "'")
 		}
 
@@ -407,6 +518,77 @@ func (n *node) insertChild(numParams uint8, path string, fullPath string, handle
 	n.fullPath = fullPath
 }
 
+// insertCatchAllSibling adds the catch-all wildcard below n, whose path ends
+// with the '/' the catch-all starts with. The static routes already stored in
+// n are moved to a '/' child of the catch-all node, so both can be matched.
+func (n *node) insertCatchAllSibling(wildcard string, fullPath string, handlers HandlersChain) {
+	static := &node{
+		path:     "/",
+		indices:  n.indices,
+		children: n.children,
+		handlers: n.handlers,
+		priority: n.priority - 1,
+		fullPath: n.fullPath,
+	}
+	for _, v := range static.children {
+		if v.maxParams > static.maxParams {
+			static.maxParams = v.maxParams
+		}
+	}
+
+	child := &node{
+		wildChild: true,
+		nType:     catchAll,
+		indices:   string('/'),
+		priority:  n.priority,
+		maxParams: 1,
+		fullPath:  fullPath,
+	}
+	if static.maxParams > child.maxParams {
+		child.maxParams = static.maxParams
+	}
+	child.children = []*node{static, {
+		path:      "/" + wildcard,
+		nType:     catchAll,
+		maxParams: 1,
+		handlers:  handlers,
+		priority:  1,
+		fullPath:  fullPath,
+	}}
+
+	n.path = n.path[:len(n.path)-1]
+	n.indices = string('/')
+	n.handlers = nil
+	n.children = []*node{child}
+}
+
+// separator returns what follows the param n is the child of: "/" or the
+// separator of params sharing a segment, e.g. "." for /:name.:ext.
+func (n *node) separator() string {
+	if len(n.path) == 0 || n.path[0] == '/' {
+		return "/"
+	}
+	return n.path
+}
+
+// paramEnd returns the end of the value of param n at the start of path,
+// either the next '/' or the separator of the next param in the segment.
+func (n *node) paramEnd(path string) int {
+	end := 0
+	for end < len(path) && path[end] != '/' {
+		end++
+	}
+
+	if len(n.children) == 1 {
+		if sep := n.children[0].separator(); sep != "/" && end > 0 {
+			if i := strings.Index(path[1:end], sep); i >= 0 {
+				return i + 1
+			}
+		}
+	}
+	return end
+}
+
 // nodeValue holds return values of (*Node).getValue method
 type nodeValue struct {
 	handlers HandlersChain
@@ -420,8 +602,16 @@ type nodeValue struct {
 // If no handle can be found, a TSR (trailing slash redirect) recommendation is
 // made if a handle exists with an extra (without the) trailing slash for the
 // given path.
+// Static routes registered next to a catch-all are preferred, the catch-all
+// only handles the request if none of them matches.
 func (n *node) getValue(path string, po Params, unescape bool) (value nodeValue) {
 	value.params = po
+
+	// the deepest catch-all skipped in favor of its static siblings
+	var skipped *node
+	var skippedPath string
+	var skippedParams int
+
 walk: // Outer loop for walking the tree
 	for {
 		prefix := n.path
@@ -435,7 +625,7 @@ walk: // Outer loop for walking the tree
 
 			if path == "/" && n.wildChild && n.nType != root {
 				value.tsr = true
-				return
+				break walk
 			}
 
 			// No handle found. Check if a handle for this path + a
@@ -445,12 +635,12 @@ walk: // Outer loop for walking the tree
 				if indices[i] == '/' {
 					n = n.children[i]
 					value.tsr = (len(n.path) == 1 && n.handlers != nil) ||
-						(n.nType == catchAll && n.children[0].handlers != nil)
-					return
+						(n.nType == catchAll && n.children[len(n.children)-1].handlers != nil)
+					break walk
 				}
 			}
 
-			return
+			break walk
 		}
 
 		if len(path) > len(prefix) && path[:len(prefix)] == prefix {
@@ -472,18 +662,31 @@ walk: // Outer loop for walking the tree
 				// We can recommend to redirect to the same URL without a
 				// trailing slash if a leaf exists for that path.
 				value.tsr = path == "/" && n.handlers != nil
-				return
+				break walk
+			}
+
+			// Static siblings of a catch-all are tried first, remember
+			// the catch-all in case none of them matches.
+			if len(n.indices) > 0 {
+				c := path[0]
+				indices := n.indices
+				for i, max := 0, len(indices); i < max; i++ {
+					if c == indices[i] {
+						skipped = n
+						skippedPath = path
+						skippedParams = len(value.params)
+						n = n.children[i]
+						continue walk
+					}
+				}
 			}
 
 			// handle wildcard child
-			n = n.children[0]
+			n = n.children[len(n.children)-1]
 			switch n.nType {
 			case param:
-				// find param end (either '/' or path end)
-				end := 0
-				for end < len(path) && path[end] != '/' {
-					end++
-				}
+				// find param end (either '/', separator or path end)
+				end := n.paramEnd(path)
 
 				// save param value
 				if cap(value.params) < int(n.maxParams) {
@@ -512,7 +715,7 @@ walk: // Outer loop for walking the tree
 
 					// ... but we can't
 					value.tsr = len(path) == end+1
-					return
+					break walk
 				}
 
 				if value.handlers = n.handlers; value.handlers != nil {
@@ -525,27 +728,10 @@ walk: // Outer loop for walking the tree
 					n = n.children[0]
 					value.tsr = n.path == "/" && n.handlers != nil
 				}
-				return
+				break walk
 
 			case catchAll:
-				// save param value
-				if cap(value.params) < int(n.maxParams) {
-					value.params = make(Params, 0, n.maxParams)
-				}
-				i := len(value.params)
-				value.params = value.params[:i+1] // expand slice within preallocated capacity
-				value.params[i].Key = n.path[2:]
-				if unescape {
-					var err error
-					if value.params[i].Value, err = url.QueryUnescape(path); err != nil {
-						value.params[i].Value = path // fallback, in case of error
-					}
-				} else {
-					value.params[i].Value = path
-				}
-
-				value.handlers = n.handlers
-				value.fullPath = n.fullPath
+				value.setCatchAll(n, path, unescape)
 				return
 
 			default:
@@ -558,8 +744,38 @@ walk: // Outer loop for walking the tree
 		value.tsr = (path == "/") ||
 			(len(prefix) == len(path)+1 && prefix[len(path)] == '/' &&
 				path == prefix[:len(prefix)-1] && n.handlers != nil)
-		return
+		break walk
+	}
+
+	// None of the static siblings matched, fall back to the catch-all.
+	if skipped != nil {
+		value.tsr = false
+		value.params = value.params[:skippedParams]
+		value.setCatchAll(skipped.children[len(skipped.children)-1], skippedPath, unescape)
 	}
+	return
+}
+
+// setCatchAll saves the catch-all value of path and the handle of n.
+func (value *nodeValue) setCatchAll(n *node, path string, unescape bool) {
+	// save param value
+	if cap(value.params) < int(n.maxParams) {
+		value.params = make(Params, 0, n.maxParams)
+	}
+	i := len(value.params)
+	value.params = value.params[:i+1] // expand slice within preallocated capacity
+	value.params[i].Key = n.path[2:]
+	if unescape {
+		var err error
+		if value.params[i].Value, err = url.QueryUnescape(path); err != nil {
+			value.params[i].Value = path // fallback, in case of error
+		}
+	} else {
+		value.params[i].Value = path
+	}
+
+	value.handlers = n.handlers
+	value.fullPath = n.fullPath
 }
 
 // findCaseInsensitivePath makes a case-insensitive lookup of the given path and tries to find a handler.
@@ -588,7 +804,7 @@ func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (ciPa
 					if n.indices[i] == '/' {
 						n = n.children[i]
 						if (len(n.path) == 1 && n.handlers != nil) ||
-							(n.nType == catchAll && n.children[0].handlers != nil) {
+							(n.nType == catchAll && n.children[len(n.children)-1].handlers != nil) {
 							return append(ciPath, '/'), true
 						}
 						return
@@ -600,8 +816,8 @@ func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (ciPa
 
 		// If this node does not have a wildcard (param or catchAll) child,
 		// we can just look up the next child node and continue to walk down
-		// the tree
-		if !n.wildChild {
+		// the tree. Static siblings of a catch-all are tried first too.
+		if !n.wildChild || len(n.indices) > 0 {
 			r := unicode.ToLower(rune(path[0]))
 			for i, index := range n.indices {
 				// must use recursive approach since both index and
@@ -614,20 +830,19 @@ func (n *node) findCaseInsensitivePath(path string, fixTrailingSlash bool) (ciPa
 				}
 			}
 
-			// Nothing found. We can recommend to redirect to the same URL
-			// without a trailing slash if a leaf exists for that path
-			found = fixTrailingSlash && path == "/" && n.handlers != nil
-			return
+			if !n.wildChild {
+				// Nothing found. We can recommend to redirect to the same URL
+				// without a trailing slash if a leaf exists for that path
+				found = fixTrailingSlash && path == "/" && n.handlers != nil
+				return
+			}
 		}
 
-		n = n.children[0]
+		n = n.children[len(n.children)-1]
 		switch n.nType {
 		case param:
-			// Find param end (either '/' or path end)
-			end := 0
-			for end < len(path) && path[end] != '/' {
-				end++
-			}
+			// Find param end (either '/', separator or path end)
+			end := n.paramEnd(path)
 
 			// add param value to case insensitive path
 			ciPath = append(ciPath, path[:end]...)
@@ -265,9 +265,9 @@ func TestTreeWildcardConflict(t *testing.T) {
 		{"/cmd/vet", true},
 		{"/src/*filepath", false},
 		{"/src/*filepathx", true},
-		{"/src/", true},
+		{"/src/", false},
 		{"/src1/", false},
-		{"/src1/*filepath", true},
+		{"/src1/*filepath", false},
 		{"/src2*filepath", true},
 		{"/search/:query", false},
 		{"/search/invalid", true},
@@ -285,13 +285,13 @@ func TestTreeChildConflict(t *testing.T) {
 		{"/cmd/vet", false},
 		{"/cmd/:tool/:sub", true},
 		{"/src/AUTHORS", false},
-		{"/src/*filepath", true},
+		{"/src/*filepath", false},
 		{"/user_x", false},
 		{"/user_:name", true},
 		{"/id/:id", false},
 		{"/id:id", true},
 		{"/:id", true},
-		{"/*filepath", true},
+		{"/*filepath", false},
 	}
 	testRoutes(t, routes)
 }
@@ -360,10 +360,10 @@ func TestTreeCatchAllConflict(t *testing.T) {
 	testRoutes(t, routes)
 }
 
-func TestTreeCatchAllConflictRoot(t *testing.T) {
+func TestTreeCatchAllRoot(t *testing.T) {
 	routes := []testRoute{
 		{"/", false},
-		{"/*filepath", true},
+		{"/*filepath", false},
 	}
 	testRoutes(t, routes)
 }
@@ -670,9 +670,6 @@ func TestTreeWildcardConflictEx(t *testing.T) {
 		existPath    string
 		existSegPath string
 	}{
-		{"/who/are/foo", "/foo", `/who/are/\*you`, `/\*you`},
-		{"/who/are/foo/", "/foo/", `/who/are/\*you`, `/\*you`},
-		{"/who/are/foo/bar", "/foo/bar", `/who/are/\*you`, `/\*you`},
 		{"/conxxx", "xxx", `/con:tact`, `:tact`},
 		{"/conooo/xxx", "ooo", `/con:tact`, `:tact`},
 	}
@@ -702,3 +699,225 @@ func TestTreeWildcardConflictEx(t *testing.T) {
 		}
 	}
 }
+
+func TestTreeParamNameConflict(t *testing.T) {
+	const panicMsg = "param ':name' in new path '/users/:name' conflicts with existing param ':id' in existing prefix '/users/:id', params at the same position must have the same name"
+
+	tree := &node{}
+	tree.addRoute("/users/:id", fakeHandler("/users/:id"))
+
+	recv := catchPanic(func() {
+		tree.addRoute("/users/:name", fakeHandler("/users/:name"))
+	})
+	if rs, ok := recv.(string); !ok || rs != panicMsg {
+		t.Fatalf("Expected panic '"+panicMsg+"', got '%v'", recv)
+	}
+
+	// longer param names sharing the same prefix are not the same param
+	tree = &node{}
+	tree.addRoute("/users/:id", fakeHandler("/users/:id"))
+	recv = catchPanic(func() {
+		tree.addRoute("/users/:ids/posts", fakeHandler("/users/:ids/posts"))
+	})
+	if rs, ok := recv.(string); !ok || !strings.HasPrefix(rs, "param ':ids' in new path '/users/:ids/posts'") {
+		t.Fatalf("Expected param conflict panic, got '%v'", recv)
+	}
+
+	routes := []testRoute{
+		{"/users/:id", false},
+		{"/users/:id/posts", false},
+		{"/users/:id/posts/:post", false},
+		{"/users/:id/comments/:comment", false},
+		{"/users/:id/posts/:name", true},
+	}
+	testRoutes(t, routes)
+}
+
+func TestTreeCatchAllStaticSiblings(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/files/*path",
+		"/files/info",
+		"/files/",
+		"/src/AUTHORS",
+		"/src/LICENSE",
+		"/src/*filepath",
+		"/doc/",
+		"/doc/*page",
+		"/doc/go/spec",
+		"/doc/go/*page",
+		"/cmd/:tool/",
+		"/cmd/:tool/*args",
+	}
+	for _, route := range routes {
+		recv := catchPanic(func() {
+			tree.addRoute(route, fakeHandler(route))
+		})
+		if recv != nil {
+			t.Fatalf("panic inserting route '%s': %v", route, recv)
+		}
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/files/info", false, "/files/info", nil},
+		{"/files/", false, "/files/", nil},
+		{"/files/a/b", false, "/files/*path", Params{Param{Key: "path", Value: "/a/b"}}},
+		{"/files/info/more", false, "/files/*path", Params{Param{Key: "path", Value: "/info/more"}}},
+		{"/files/inf", false, "/files/*path", Params{Param{Key: "path", Value: "/inf"}}},
+		{"/src/AUTHORS", false, "/src/AUTHORS", nil},
+		{"/src/LICENSE", false, "/src/LICENSE", nil},
+		{"/src/", false, "/src/*filepath", Params{Param{Key: "filepath", Value: "/"}}},
+		{"/src/README", false, "/src/*filepath", Params{Param{Key: "filepath", Value: "/README"}}},
+		{"/doc/", false, "/doc/", nil},
+		{"/doc/go/spec", false, "/doc/go/spec", nil},
+		{"/doc/go/faq", false, "/doc/go/*page", Params{Param{Key: "page", Value: "/faq"}}},
+		{"/doc/python", false, "/doc/*page", Params{Param{Key: "page", Value: "/python"}}},
+		{"/cmd/vet/", false, "/cmd/:tool/", Params{Param{Key: "tool", Value: "vet"}}},
+		{"/cmd/vet/a/b", false, "/cmd/:tool/*args", Params{Param{Key: "tool", Value: "vet"}, Param{Key: "args", Value: "/a/b"}}},
+	})
+
+	checkPriorities(t, tree)
+	checkMaxParams(t, tree)
+
+	// the catch-all still conflicts with params and other catch-alls
+	for _, route := range [...]string{"/files/:name", "/files/*other"} {
+		if recv := catchPanic(func() { tree.addRoute(route, nil) }); recv == nil {
+			t.Errorf("no panic for conflicting route '%s'", route)
+		}
+	}
+}
+
+func TestTreeCatchAllStaticSiblingsCaseInsensitive(t *testing.T) {
+	tree := &node{}
+	for _, route := range [...]string{"/files/*path", "/files/info"} {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	tests := []struct {
+		in    string
+		out   string
+		found bool
+	}{
+		{"/FILES/INFO", "/files/info", true},
+		{"/FILES/Other", "/files/Other", true},
+		{"/Files", "/files/", true},
+	}
+	for _, test := range tests {
+		out, found := tree.findCaseInsensitivePath(test.in, true)
+		if found != test.found || string(out) != test.out {
+			t.Errorf("Wrong result for '%s': got %s, %t; want %s, %t",
+				test.in, string(out), found, test.out, test.found)
+		}
+	}
+}
+
+func TestTreeOptionalParam(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/posts/:id?",
+		"/users/:user/repos/:repo?",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, fakeHandler(route))
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/posts", false, "/posts/:id?", nil},
+		{"/posts/42", false, "/posts/:id?", Params{Param{Key: "id", Value: "42"}}},
+		{"/posts/42/comments", true, "", Params{Param{Key: "id", Value: "42"}}},
+		{"/users/gopher/repos", false, "/users/:user/repos/:repo?", Params{Param{Key: "user", Value: "gopher"}}},
+		{"/users/gopher/repos/gin", false, "/users/:user/repos/:repo?", Params{Param{Key: "user", Value: "gopher"}, Param{Key: "repo", Value: "gin"}}},
+	})
+
+	checkPriorities(t, tree)
+	checkMaxParams(t, tree)
+
+	tree = &node{}
+	tree.addRoute("/:lang?", fakeHandler("/:lang?"))
+	checkRequests(t, tree, testRequests{
+		{"/", false, "/:lang?", nil},
+		{"/en", false, "/:lang?", Params{Param{Key: "lang", Value: "en"}}},
+	})
+}
+
+func TestTreeOptionalParamConflict(t *testing.T) {
+	const panicMsg = "optional params are only allowed as the last path segment"
+
+	routes := [...]string{
+		"/posts/:id?/comments",
+		"/user_:name?",
+		"/src/*filepath?",
+	}
+	for _, route := range routes {
+		tree := &node{}
+		recv := catchPanic(func() {
+			tree.addRoute(route, nil)
+		})
+		if rs, ok := recv.(string); !ok || !strings.HasPrefix(rs, panicMsg) {
+			t.Fatalf(`Expected panic "%s" for route '%s', got "%v"`, panicMsg, route, recv)
+		}
+	}
+
+	// the expanded routes conflict with existing ones like any other route
+	tree := &node{}
+	tree.addRoute("/posts", fakeHandler("/posts"))
+	recv := catchPanic(func() {
+		tree.addRoute("/posts/:id?", fakeHandler("/posts/:id?"))
+	})
+	if recv == nil {
+		t.Fatalf("no panic while inserting duplicate route '/posts/:id?'")
+	}
+}
+
+func TestTreeMultipleParamsInSegment(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/files/:name.:ext",
+		"/files/:name",
+		"/range/:from-:to/items",
+		"/v:major.:minor.:patch",
+	}
+	for _, route := range routes {
+		recv := catchPanic(func() {
+			tree.addRoute(route, fakeHandler(route))
+		})
+		if recv != nil {
+			t.Fatalf("panic inserting route '%s': %v", route, recv)
+		}
+	}
+
+	checkRequests(t, tree, testRequests{
+		{"/files/report.pdf", false, "/files/:name.:ext", Params{Param{Key: "name", Value: "report"}, Param{Key: "ext", Value: "pdf"}}},
+		{"/files/report.tar.gz", false, "/files/:name.:ext", Params{Param{Key: "name", Value: "report"}, Param{Key: "ext", Value: "tar.gz"}}},
+		{"/files/.bashrc", false, "/files/:name", Params{Param{Key: "name", Value: ".bashrc"}}},
+		{"/files/report", false, "/files/:name", Params{Param{Key: "name", Value: "report"}}},
+		{"/range/1-10/items", false, "/range/:from-:to/items", Params{Param{Key: "from", Value: "1"}, Param{Key: "to", Value: "10"}}},
+		{"/range/10/items", true, "", Params{Param{Key: "from", Value: "10"}}},
+		{"/v1.2.3", false, "/v:major.:minor.:patch", Params{Param{Key: "major", Value: "1"}, Param{Key: "minor", Value: "2"}, Param{Key: "patch", Value: "3"}}},
+	})
+
+	checkPriorities(t, tree)
+	checkMaxParams(t, tree)
+
+	out, found := tree.findCaseInsensitivePath("/FILES/Report.PDF", true)
+	if !found || string(out) != "/files/Report.PDF" {
+		t.Errorf("Wrong result for '/FILES/Report.PDF': got %s, %t", string(out), found)
+	}
+}
+
+func TestTreeMultipleParamsInSegmentConflict(t *testing.T) {
+	routes := []testRoute{
+		{"/files/:name.:ext", false},
+		{"/files/:name.:extension", true},
+		{"/files/:name-:ext", true},
+		{"/files/:name.txt", true},
+		{"/files/:name/meta", true},
+		{"/range/:from-:to", false},
+		{"/range/:from:to", true},
+		{"/range/:from-*to", true},
+	}
+	testRoutes(t, routes)
+}
@@ -6,12 +6,15 @@ package gin
 
 import (
 	"encoding/xml"
+	"errors"
 	"net/http"
 	"os"
 	"path"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 )
 
 // BindKey indicates a default bind key.
@@ -99,17 +102,49 @@ func chooseData(custom, wildcard interface{}) interface{} {
 	return custom
 }
 
+// parseAccept returns the media ranges of an Accept header ordered by their
+// q-value, the ranges of equal quality keep the header order and q=0 ones are dropped.
 func parseAccept(acceptHeader string) []string {
 	parts := strings.Split(acceptHeader, ",")
 	out := make([]string, 0, len(parts))
+	quality := make([]float64, 0, len(parts))
 	for _, part := range parts {
-		if part = strings.TrimSpace(strings.Split(part, ";")[0]); part != "" {
+		params := ""
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			part, params = part[:i], part[i+1:]
+		}
+		q := qValue(params)
+		if part = strings.TrimSpace(part); part != "" && q > 0 {
 			out = append(out, part)
+			quality = append(quality, q)
+		}
+	}
+	// insertion sort, the headers are short and the sort must be stable
+	for i := 1; i < len(out); i++ {
+		for j := i; j > 0 && quality[j] > quality[j-1]; j-- {
+			out[j], out[j-1] = out[j-1], out[j]
+			quality[j], quality[j-1] = quality[j-1], quality[j]
 		}
 	}
 	return out
 }
 
+// qValue returns the q parameter of the ";" separated params of an Accept
+// header entry, 1 when missing and 0 when malformed. Only the first q counts.
+func qValue(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		param = strings.Replace(param, " ", "", -1)
+		if v := strings.TrimPrefix(param, "q="); v != param {
+			q, err := strconv.ParseFloat(v, 64)
+			if err != nil || q < 0 {
+				return 0
+			}
+			return q
+		}
+	}
+	return 1
+}
+
 func lastChar(str string) uint8 {
 	if str == "" {
 		panic("The length of the string can't be 0")
@@ -149,3 +184,17 @@ func resolveAddress(addr []string) string {
 		panic("too many parameters")
 	}
 }
+
+// isBrokenPipe reports whether err comes from writing to a connection the client closed.
+func isBrokenPipe(err error) bool {
+	var errno syscall.Errno
+	if errors.As(err, &errno) && (errno == syscall.EPIPE || errno == syscall.ECONNRESET) {
+		return true
+	}
+	var se *os.SyscallError
+	if errors.As(err, &se) {
+		msg := strings.ToLower(se.Error())
+		return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+	}
+	return false
+}
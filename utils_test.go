@@ -7,8 +7,12 @@ package gin
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"syscall"
 	"testing"
 
 	"github.com/go-playground/assert"
@@ -61,6 +65,18 @@ func TestParseAccept(t *testing.T) {
 	assert.Equal(t, "application/xhtml+xml", parts[1])
 	assert.Equal(t, "application/xml", parts[2])
 	assert.Equal(t, "*/*", parts[3])
+
+	parts = parseAccept("text/html;q=0.8, application/json;level=1;q=0.5, application/xml;q=0.9;q=0.1, text/plain;q=0, */*")
+	assert.Equal(t, []string{"*/*", "application/xml", "text/html", "application/json"}, parts)
+}
+
+func TestQValue(t *testing.T) {
+	assert.Equal(t, 1.0, qValue(""))
+	assert.Equal(t, 1.0, qValue("level=1"))
+	assert.Equal(t, 0.5, qValue("level=1; q=0.5"))
+	assert.Equal(t, 0.9, qValue("q=0.9;q=0.8"))
+	assert.Equal(t, 0.0, qValue("q=0"))
+	assert.Equal(t, 0.0, qValue("q=high"))
 }
 
 func TestChooseData(t *testing.T) {
@@ -137,3 +153,12 @@ func TestMarshalXMLforH(t *testing.T) {
 	e := h.MarshalXML(enc, x)
 	assert.NotEqual(t, nil, e)
 }
+
+func TestIsBrokenPipe(t *testing.T) {
+	assert.Equal(t, true, isBrokenPipe(syscall.EPIPE))
+	assert.Equal(t, true, isBrokenPipe(&net.OpError{Err: &os.SyscallError{Err: syscall.ECONNRESET}}))
+	assert.Equal(t, true, isBrokenPipe(fmt.Errorf("write: %w", &os.SyscallError{Syscall: "write", Err: errors.New("broken pipe")})))
+	assert.Equal(t, false, isBrokenPipe(&net.OpError{Err: &os.SyscallError{Err: syscall.ETIMEDOUT}}))
+	assert.Equal(t, false, isBrokenPipe(errors.New("broken pipe")))
+	assert.Equal(t, false, isBrokenPipe(nil))
+}